@@ -7,179 +7,468 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-	"time"
+	"sync"
 
 	"github.com/ty-e-boyd/thepaper/models"
-	"google.golang.org/genai"
 )
 
-// Analyzer uses Gemini AI to select and summarize articles
+// candidateMultiplier sets how many top-scored articles (topN × multiplier) are tagged
+// and considered for selection
+const candidateMultiplier = 3
+
+// Analyzer uses an LLM provider to select and summarize articles
 type Analyzer struct {
-	client          *genai.Client
-	rateLimitDelay  time.Duration
-	lastRequestTime time.Time
+	provider       Provider
+	limiter        *RateLimiter
+	concurrency    int     // Number of requests in flight at once
+	scoreBatchSize int     // Articles per scoring prompt; 1 or less scores one article per call
+	dedupThreshold float64 // Embedding similarity for merging stories; 0 disables
+	coverageBoost  float64 // Score added per doubling of sources covering a story
+	retry          RetryPolicy
+	policy         models.SelectionPolicy // Quotas and thresholds for picking articles
+	calibration    string                 // Score calibration method, e.g. CalibrationPercentile
+	prompts        *Prompts
+	cache          Cache            // Optional; nil disables caching
+	extractor      ContentExtractor // Optional; nil summarizes the feed content
+	recorder       DecisionRecorder // Receives per-article decisions of the shared ranking
+	history        ScoreHistory     // Optional; nil calibrates each run against itself
+	summaryStyles  []string         // Summary styles generated in addition to the one-liner
+	inputPrice     float64          // USD per million prompt tokens
+	outputPrice    float64          // USD per million output tokens
+	dailyBudget    float64          // USD per day across runs; 0 disables the budget
+	budgetMode     string           // BudgetDegrade or BudgetAbort
+	spentToday     float64          // Spend of earlier runs today
+
+	mu                 sync.Mutex // Guards the per-run stats below
+	tagStats           tagStats
+	cacheStats         cacheStats
+	extractStats       extractStats
+	scoreErrors        int                           // Articles that could not be scored and were given 0
+	summaries          map[string]string             // Summaries generated for the current ranking, by article link and style
+	extracted          map[string]string             // Full text extracted for the current ranking, by article link; empty if extraction failed
+	usage              map[string]*models.StageUsage // Token usage of the current run, by stage
+	dedupedByEmbedding bool                          // Whether the current ranking was deduplicated semantically
 }
 
-// NewAnalyzer creates a new Gemini-powered analyzer with rate limiting
-func NewAnalyzer(ctx context.Context, apiKey string, rateLimitDelay time.Duration) (*Analyzer, error) {
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  apiKey,
-		Backend: genai.BackendGeminiAPI,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+// NewAnalyzer creates a new analyzer backed by the given provider, configured with
+// rate limiting, concurrency, batching and selection settings from cfg
+func NewAnalyzer(provider Provider, cfg *models.Config) *Analyzer {
+	concurrency := cfg.AIConcurrency
+	if concurrency < 1 {
+		concurrency = 1
 	}
 
-	return &Analyzer{
-		client:          client,
-		rateLimitDelay:  rateLimitDelay,
-		lastRequestTime: time.Now(),
-	}, nil
+	analyzer := &Analyzer{
+		provider:       provider,
+		limiter:        NewRateLimiter(cfg.AIRequestsPerMinute, cfg.AITokensPerMinute),
+		concurrency:    concurrency,
+		scoreBatchSize: cfg.ScoreBatchSize,
+		dedupThreshold: cfg.DedupThreshold,
+		coverageBoost:  cfg.CoverageBoost,
+		calibration:    cfg.ScoreCalibration,
+		retry: RetryPolicy{
+			MaxAttempts: cfg.AIRetryMaxAttempts,
+			MaxElapsed:  cfg.AIRetryMaxElapsed,
+		},
+		prompts:     DefaultPrompts(),
+		inputPrice:  cfg.AIPriceInputPerMTok,
+		outputPrice: cfg.AIPriceOutputPerMTok,
+		dailyBudget: cfg.AIDailyBudgetUSD,
+		budgetMode:  cfg.AIBudgetMode,
+		recorder:    nopRecorder{},
+		summaries:   make(map[string]string),
+		extracted:   make(map[string]string),
+		usage:       make(map[string]*models.StageUsage),
+	}
+
+	// Configs not read by config.Load have no policy or calibration method
+	if analyzer.calibration == "" {
+		analyzer.calibration = CalibrationPercentile
+	}
+	policy := cfg.Selection
+	if policy.TopN == 0 {
+		policy = models.DefaultSelectionPolicy()
+	}
+	analyzer.SetSelectionPolicy(policy)
+	return analyzer
+}
+
+// SetPrompts replaces the built-in prompt templates
+func (a *Analyzer) SetPrompts(prompts *Prompts) {
+	a.prompts = prompts
+}
+
+// PromptVersion identifies the prompt templates the analyzer uses
+func (a *Analyzer) PromptVersion() string {
+	return a.prompts.Version()
 }
 
 // Close cleans up the analyzer resources
 func (a *Analyzer) Close() {
-	// Client cleanup if needed
+	if err := a.provider.Close(); err != nil {
+		log.Printf("Warning: Failed to close %s provider: %v", a.provider.Name(), err)
+	}
+}
+
+// generate rate limits and sends a single request to the provider
+func (a *Analyzer) generate(ctx context.Context, task Task, prompt string) (string, error) {
+	return a.generateStructured(ctx, task, prompt, nil)
 }
 
-// rateLimit ensures we don't exceed API rate limits
-func (a *Analyzer) rateLimit() {
-	elapsed := time.Since(a.lastRequestTime)
-	if elapsed < a.rateLimitDelay {
-		time.Sleep(a.rateLimitDelay - elapsed)
+// generateStructured rate limits and sends a request for JSON output matching schema
+func (a *Analyzer) generateStructured(ctx context.Context, task Task, prompt string, schema *Schema) (string, error) {
+	if err := a.limiter.Wait(ctx, estimateTokens(prompt)+outputTokenAllowance); err != nil {
+		return "", err
 	}
-	a.lastRequestTime = time.Now()
+
+	response, err := a.provider.Generate(ctx, Request{Task: task, Prompt: prompt, Schema: schema})
+	if err != nil {
+		return "", err
+	}
+	a.recordUsage(task, response.Usage)
+	return strings.TrimSpace(response.Text), nil
 }
 
-// retryWithBackoff retries a function with exponential backoff on rate limit errors
-func retryWithBackoff(ctx context.Context, maxRetries int, fn func() error) error {
-	var lastErr error
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		if attempt > 0 {
-			// Exponential backoff: 1s, 2s, 4s, 8s, 16s
-			backoff := time.Duration(1<<uint(attempt-1)) * time.Second
-			log.Printf("  Retry attempt %d/%d after %v...", attempt, maxRetries, backoff)
-			time.Sleep(backoff)
-		}
+// runFailer is implemented by providers that can fail a whole run, such as
+// ReplayProvider, whose errors must not be absorbed by per-article fallbacks
+type runFailer interface {
+	Err() error
+}
 
-		err := fn()
-		if err == nil {
-			return nil
-		}
+// runErr returns the context's error or the provider's run-level error, if any
+func (a *Analyzer) runErr(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if failer, ok := a.provider.(runFailer); ok {
+		return failer.Err()
+	}
+	return nil
+}
 
-		lastErr = err
-		// Check if it's a rate limit error
-		if !strings.Contains(err.Error(), "RESOURCE_EXHAUSTED") && !strings.Contains(err.Error(), "429") {
-			// Not a rate limit error, don't retry
-			return err
+// parallel calls fn for each index in [0, n) on a bounded pool of workers.
+// Indices not yet started when ctx is cancelled are skipped.
+func (a *Analyzer) parallel(ctx context.Context, n int, fn func(i int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(a.concurrency, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+	defer func() {
+		close(jobs)
+		wg.Wait()
+	}()
+	for i := 0; i < n; i++ {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			return
 		}
 	}
-	return fmt.Errorf("max retries exceeded: %w", lastErr)
 }
 
 // SelectAndSummarize analyzes articles, scores them for relevance, and summarizes the top ones
 func (a *Analyzer) SelectAndSummarize(ctx context.Context, articles []models.Article, topN int) ([]models.AnalyzedArticle, error) {
+	ranked, err := a.RankArticles(ctx, articles, topN)
+	if err != nil {
+		return nil, err
+	}
+
+	selected, err := a.SelectFromRanked(ctx, ranked, topN)
+	if err != nil {
+		return nil, err
+	}
+
+	a.LogStats()
+	return selected, nil
+}
+
+// LogStats logs usage statistics for the current run
+func (a *Analyzer) LogStats() {
+	log.Println()
+	a.logUsage()
+	if a.cache != nil {
+		log.Printf("\nLLM cache: %d hits, %d misses, ~%d tokens saved", a.cacheStats.hits, a.cacheStats.misses, a.cacheStats.tokensSaved)
+	}
+	if a.extractor != nil {
+		log.Printf("Full-text extraction: %d extracted, %d kept feed content", a.extractStats.extracted, a.extractStats.fallbacks)
+	}
+}
+
+// RankArticles scores all articles once, sorts them by relevance, and tags the top
+// candidates (more than topN, to leave room for diversity and per-user selection)
+func (a *Analyzer) RankArticles(ctx context.Context, articles []models.Article, topN int) ([]models.AnalyzedArticle, error) {
 	if len(articles) == 0 {
 		return nil, fmt.Errorf("no articles to analyze")
 	}
 
-	// Score all articles for relevance
-	log.Printf("Scoring %d articles...", len(articles))
-	analyzed := make([]models.AnalyzedArticle, len(articles))
-	for i, article := range articles {
-		score, err := a.scoreArticle(ctx, article)
-		if err != nil {
-			log.Printf("  ✗ Error scoring '%s' from %s: %v", article.Title, article.Source, err)
-			score = 0
-		} else {
-			log.Printf("  %.1f - %s (from %s)", score, article.Title, article.Source)
-		}
+	a.cacheStats = cacheStats{}
+	a.extractStats = extractStats{}
+	a.scoreErrors = 0
+	a.summaries = make(map[string]string)
+	a.extracted = make(map[string]string)
+	a.usage = make(map[string]*models.StageUsage)
 
-		analyzed[i] = models.AnalyzedArticle{
-			Article:        article,
-			RelevanceScore: score,
-			Selected:       false,
-		}
+	if _, err := a.checkBudget("scoring"); err != nil {
+		return nil, err
+	}
+
+	// Score all articles for relevance
+	log.Printf("Scoring %d articles with %d worker(s)...", len(articles), a.concurrency)
+	analyzed, scored := a.scoreArticles(ctx, articles)
+	if err := a.runErr(ctx); err != nil {
+		return nil, err
 	}
+	a.calibrateScores(analyzed, scored)
 
 	// Sort by relevance score
 	sort.Slice(analyzed, func(i, j int) bool {
 		return analyzed[i].RelevanceScore > analyzed[j].RelevanceScore
 	})
 
+	// Group articles covering the same story before selection
+	a.dedupedByEmbedding = false
+	if a.dedupThreshold > 0 {
+		log.Printf("\nGrouping %d articles by story (similarity ≥ %.2f)...", len(analyzed), a.dedupThreshold)
+		stories, err := a.dedupeStories(ctx, analyzed)
+		if err != nil {
+			if err := a.runErr(ctx); err != nil {
+				return nil, err
+			}
+			log.Printf("  ✗ Semantic deduplication failed, falling back to title matching: %v", err)
+		} else {
+			log.Printf("Semantic deduplication: %d articles → %d stories", len(analyzed), len(stories))
+			a.applyCoverageBoost(stories)
+			analyzed = stories
+			a.dedupedByEmbedding = true
+		}
+	}
+
 	// Extract tags and categories for top candidates (check more than topN for diversity)
-	candidateCount := topN * 3
-	if candidateCount > len(analyzed) {
-		candidateCount = len(analyzed)
+	candidateCount := min(topN*candidateMultiplier, len(analyzed))
+	degrade, err := a.checkBudget("tagging")
+	if err != nil {
+		return nil, err
+	}
+	if degrade {
+		candidateCount = min(topN, len(analyzed))
+		log.Printf("  Tagging only the top %d articles to save budget", candidateCount)
 	}
 
 	log.Printf("\nExtracting tags and categories for top %d candidates...", candidateCount)
-	for i := 0; i < candidateCount; i++ {
+	a.tagStats = tagStats{}
+	a.parallel(ctx, candidateCount, func(i int) {
 		tags, category, err := a.extractTagsAndCategory(ctx, analyzed[i].Article)
 		if err != nil {
-			log.Printf("  ✗ Error extracting tags for '%s': %v", analyzed[i].Title, err)
+			log.Printf("  ✗ Error extracting tags for '%s', using fallback category %s: %v", analyzed[i].Title, fallbackCategory, err)
+			a.mu.Lock()
+			a.tagStats.fallbacks++
+			a.mu.Unlock()
 			tags = []string{}
-			category = "General"
+			category = fallbackCategory
 		} else {
 			log.Printf("  ✓ '%s' → Category: %s, Tags: %v", analyzed[i].Title, category, tags)
 		}
 		analyzed[i].Tags = tags
 		analyzed[i].Category = category
+		a.recorder.Tagged(analyzed[i], err)
+	})
+	if err := a.runErr(ctx); err != nil {
+		return nil, err
 	}
+	log.Printf("Tagging: %d/%d valid, %d corrective retries, %d invalid responses, %d fell back to %s",
+		candidateCount-a.tagStats.fallbacks, candidateCount, a.tagStats.corrections, a.tagStats.invalid, a.tagStats.fallbacks, fallbackCategory)
 
+	return analyzed, nil
+}
+
+// SelectFromRanked picks the top N articles from a ranked list with diversity constraints
+// and summarizes them. Summaries are reused across calls for the same ranked list.
+func (a *Analyzer) SelectFromRanked(ctx context.Context, ranked []models.AnalyzedArticle, topN int) ([]models.AnalyzedArticle, error) {
+	return a.selectFromRanked(ctx, ranked, topN, a.recorder)
+}
+
+// selectFromRanked is SelectFromRanked with the selection decisions sent to recorder
+func (a *Analyzer) selectFromRanked(ctx context.Context, ranked []models.AnalyzedArticle, topN int, recorder DecisionRecorder) ([]models.AnalyzedArticle, error) {
 	// Select top N articles with diversity constraints
-	selected := a.selectWithDiversity(analyzed, topN)
+	selected := a.selectWithDiversity(ranked, topN, recorder)
 
 	log.Printf("\nSelected %d articles with category diversity:", len(selected))
 	for i, article := range selected {
 		log.Printf("  %d. [%.1f] %s (Category: %s)", i+1, article.RelevanceScore, article.Title, article.Category)
 	}
 
-	// Summarize selected articles
-	log.Printf("\nGenerating summaries...")
+	if _, err := a.checkBudget("summarizing"); err != nil {
+		return nil, err
+	}
+
+	a.extractContent(ctx, selected)
+
+	// Summarize selected articles in every requested style
+	styles := append([]string{models.SummaryOneLiner}, a.summaryStyles...)
 	for i := range selected {
 		selected[i].Selected = true
-		summary, err := a.summarizeArticle(ctx, selected[i].Article)
+	}
+
+	log.Printf("\nGenerating summaries (%s)...", strings.Join(styles, ", "))
+	a.parallel(ctx, len(selected)*len(styles), func(job int) {
+		i, style := job/len(styles), styles[job%len(styles)]
+		memoKey := selected[i].Link + "\n" + style
+
+		a.mu.Lock()
+		summary, ok := a.summaries[memoKey]
+		a.mu.Unlock()
+		if ok {
+			applySummary(&selected[i], style, summary)
+			return
+		}
+
+		summary, err := a.summarizeArticle(ctx, selected[i].Article, style)
 		if err != nil {
-			log.Printf("  ✗ Error summarizing '%s': %v", selected[i].Title, err)
+			log.Printf("  ✗ Error summarizing '%s' (%s): %v", selected[i].Title, style, err)
+			if style != models.SummaryOneLiner {
+				return // Rendering falls back to the one-liner
+			}
 			summary = "Summary unavailable."
 		} else {
-			log.Printf("  ✓ Summarized '%s'", selected[i].Title)
+			log.Printf("  ✓ Summarized '%s' (%s)", selected[i].Title, style)
+			a.mu.Lock()
+			a.summaries[memoKey] = summary
+			a.mu.Unlock()
 		}
-		selected[i].Summary = summary
+		applySummary(&selected[i], style, summary)
+	})
+	if err := a.runErr(ctx); err != nil {
+		return nil, err
 	}
 
 	return selected, nil
 }
 
-// scoreArticle uses Gemini to score an article's relevance for programming/tech news
-func (a *Analyzer) scoreArticle(ctx context.Context, article models.Article) (float64, error) {
-	prompt := fmt.Sprintf(`Rate the following article's relevance for a daily programming and technology newsletter on a scale of 0-10.
-Consider:
-- Technical depth and value
-- Relevance to software developers
-- Timeliness and importance
-- Novelty and interest
+// scoreArticles scores every article, sending batched prompts when a batch size is
+// configured and falling back to per-article scoring for anything a batch missed. It
+// also reports which articles were scored; the others failed and were given 0.
+func (a *Analyzer) scoreArticles(ctx context.Context, articles []models.Article) ([]models.AnalyzedArticle, []bool) {
+	analyzed := make([]models.AnalyzedArticle, len(articles))
+	scored := make([]bool, len(articles))
+	for i, article := range articles {
+		analyzed[i] = models.AnalyzedArticle{
+			Article:  article,
+			Selected: false,
+		}
+	}
 
-Article:
-Title: %s
-Description: %s
+	// Reuse cached scores and only send the remaining articles to the provider
+	var pending []int
+	for i, article := range articles {
+		prompt, _ := a.scorePrompt(article)
+		if text, ok := a.cacheLookup(TaskScore, article, prompt); ok {
+			if score, err := strconv.ParseFloat(text, 64); err == nil {
+				analyzed[i].RelevanceScore = score
+				scored[i] = true
+				a.recorder.Scored(article, score, true, nil)
+				log.Printf("  %.1f - %s (from %s, cached)", score, article.Title, article.Source)
+				continue
+			}
+		}
+		pending = append(pending, i)
+	}
 
-Respond with ONLY a number between 0 and 10. You may use half increments (e.g., 7.5, 8.5, 9.5).`, article.Title, article.Description)
+	batches := 0
+	if a.scoreBatchSize > 1 {
+		batches = (len(pending) + a.scoreBatchSize - 1) / a.scoreBatchSize
+		a.parallel(ctx, batches, func(b int) {
+			start := b * a.scoreBatchSize
+			end := min(start+a.scoreBatchSize, len(pending))
 
-	var score float64
-	err := retryWithBackoff(ctx, 5, func() error {
-		// Rate limit before making request
-		a.rateLimit()
+			batch := make([]models.Article, 0, end-start)
+			for _, i := range pending[start:end] {
+				batch = append(batch, articles[i])
+			}
+
+			scores, err := a.scoreBatch(ctx, batch)
+			if err != nil {
+				log.Printf("  ✗ Error scoring batch %d-%d, falling back to per-article scoring: %v", start+1, end, err)
+				return
+			}
+
+			for offset, score := range scores {
+				i := pending[start+offset]
+				analyzed[i].RelevanceScore = score
+				scored[i] = true
+				a.cacheStore(TaskScore, articles[i], strconv.FormatFloat(score, 'f', -1, 64))
+				a.recorder.Scored(articles[i], score, false, nil)
+				log.Printf("  %.1f - %s (from %s)", score, articles[i].Title, articles[i].Source)
+			}
+			if missing := len(batch) - len(scores); missing > 0 {
+				log.Printf("  ⚠ Batch %d-%d returned no valid score for %d article(s), scoring individually", start+1, end, missing)
+			}
+		})
+	}
+
+	var unscored []int
+	for _, i := range pending {
+		if !scored[i] {
+			unscored = append(unscored, i)
+		}
+	}
 
-		content := []*genai.Content{{Parts: []*genai.Part{genai.NewPartFromText(prompt)}}}
-		response, err := a.client.Models.GenerateContent(ctx, "gemini-2.0-flash", content, nil)
+	a.parallel(ctx, len(unscored), func(n int) {
+		i := unscored[n]
+		article := articles[i]
+		score, err := a.scoreArticle(ctx, article)
+		if err != nil {
+			log.Printf("  ✗ Error scoring '%s' from %s: %v", article.Title, article.Source, err)
+			a.mu.Lock()
+			a.scoreErrors++
+			a.mu.Unlock()
+			score = 0
+		} else {
+			a.cacheStore(TaskScore, article, strconv.FormatFloat(score, 'f', -1, 64))
+			log.Printf("  %.1f - %s (from %s)", score, article.Title, article.Source)
+			scored[i] = true
+		}
+		a.recorder.Scored(article, score, false, err)
+		analyzed[i].RelevanceScore = score
+	})
+	fallbacks := len(unscored)
+
+	if a.scoreBatchSize > 1 {
+		calls := batches + fallbacks
+		log.Printf("Batch scoring: %d calls for %d uncached articles (%d batches of up to %d, %d per-article fallbacks), %d calls saved",
+			calls, len(pending), batches, a.scoreBatchSize, fallbacks, len(pending)-calls)
+	}
+
+	return analyzed, scored
+}
+
+// scorePrompt builds the prompt for scoring a single article
+func (a *Analyzer) scorePrompt(article models.Article) (string, error) {
+	return a.prompts.render(promptScore, PromptData{Title: article.Title, Description: article.Description})
+}
+
+// scoreArticle uses the provider to score an article's relevance for programming/tech news
+func (a *Analyzer) scoreArticle(ctx context.Context, article models.Article) (float64, error) {
+	prompt, err := a.scorePrompt(article)
+	if err != nil {
+		return 0, err
+	}
+
+	var score float64
+	err = a.retry.Do(ctx, func() error {
+		scoreStr, err := a.generate(ctx, TaskScore, prompt)
 		if err != nil {
 			return fmt.Errorf("failed to score article: %w", err)
 		}
 
 		// Extract score from response
-		scoreStr := strings.TrimSpace(response.Text())
 		parsedScore, err := strconv.ParseFloat(scoreStr, 64)
 		if err != nil {
 			return fmt.Errorf("invalid score format: %s", scoreStr)
@@ -194,136 +483,134 @@ Respond with ONLY a number between 0 and 10. You may use half increments (e.g.,
 	return score, nil
 }
 
-// summarizeArticle uses Gemini to create a concise summary
-func (a *Analyzer) summarizeArticle(ctx context.Context, article models.Article) (string, error) {
-	prompt := fmt.Sprintf(`Summarize the following article in ONE concise sentence for a technical audience.
-Focus on the single most important technical point or takeaway.
-
-Article:
-Title: %s
-Content: %s
-
-Summary:`, article.Title, article.Content)
-
-	var summary string
-	err := retryWithBackoff(ctx, 5, func() error {
-		// Rate limit before making request
-		a.rateLimit()
-
-		content := []*genai.Content{{Parts: []*genai.Part{genai.NewPartFromText(prompt)}}}
-		response, err := a.client.Models.GenerateContent(ctx, "gemini-2.0-flash", content, nil)
-		if err != nil {
-			return fmt.Errorf("failed to summarize article: %w", err)
-		}
+// summarizeArticle uses the provider to summarize an article in the given style.
+// Responses that are malformed or over the style's length limit are re-requested with
+// a corrective prompt, and truncated if the model still overshoots.
+func (a *Analyzer) summarizeArticle(ctx context.Context, article models.Article, styleName string) (string, error) {
+	style, ok := summaryStyles[styleName]
+	if !ok {
+		return "", fmt.Errorf("unknown summary style %q", styleName)
+	}
 
-		summary = strings.TrimSpace(response.Text())
-		return nil
+	basePrompt, err := a.prompts.render(style.prompt, PromptData{
+		Title:     article.Title,
+		Content:   article.Content,
+		MaxLength: style.maxLength,
 	})
-
 	if err != nil {
 		return "", err
 	}
-	return summary, nil
-}
 
-// extractTagsAndCategory uses Gemini to extract relevant tags and categorize the article
-func (a *Analyzer) extractTagsAndCategory(ctx context.Context, article models.Article) ([]string, string, error) {
-	prompt := fmt.Sprintf(`Analyze this article and provide:
-1. A category (ONE of: AI/ML, Web Development, Backend, DevOps, Mobile, Security, Data, Cloud, Open Source, Career, General)
-2. 2-3 relevant tags (short keywords)
+	if summary, ok := a.cacheLookup(style.task, article, basePrompt); ok {
+		return summary, nil
+	}
 
-Article:
-Title: %s
-Description: %s
+	prompt := basePrompt
+	var responseText string
+	var validationErr error
+	for attempt := 0; attempt <= maxSummaryCorrections; attempt++ {
+		if attempt > 0 {
+			log.Printf("  ↻ Invalid %s summary for '%s' (%v), retrying with corrective prompt (%d/%d)", styleName, article.Title, validationErr, attempt, maxSummaryCorrections)
+		}
 
-Respond in this EXACT format:
-Category: [category]
-Tags: [tag1, tag2, tag3]`, article.Title, article.Description)
+		err := a.retry.Do(ctx, func() error {
+			text, err := a.generateStructured(ctx, style.task, prompt, style.schema)
+			if err != nil {
+				return fmt.Errorf("failed to summarize article: %w", err)
+			}
 
-	var responseText string
-	err := retryWithBackoff(ctx, 5, func() error {
-		// Rate limit before making request
-		a.rateLimit()
+			responseText = text
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
 
-		content := []*genai.Content{{Parts: []*genai.Part{genai.NewPartFromText(prompt)}}}
-		response, err := a.client.Models.GenerateContent(ctx, "gemini-2.0-flash", content, nil)
+		summary, err := parseSummary(responseText, style)
+		if err == nil {
+			a.cacheStore(style.task, article, summary)
+			return summary, nil
+		}
+
+		validationErr = err
+		prompt, err = a.prompts.render(promptSummarizeCorrection, PromptData{
+			Prompt:   basePrompt,
+			Previous: responseText,
+			Error:    validationErr.Error(),
+		})
 		if err != nil {
-			return fmt.Errorf("failed to extract tags: %w", err)
+			return "", err
 		}
+	}
 
-		responseText = strings.TrimSpace(response.Text())
-		return nil
-	})
+	summary := truncateSummary(responseText, style)
+	if summary == "" {
+		return "", fmt.Errorf("invalid %s summary after %d corrective attempts: %w", styleName, maxSummaryCorrections, validationErr)
+	}
+	log.Printf("  ✂ Truncated %s summary for '%s' after %d corrective attempts (%v)", styleName, article.Title, maxSummaryCorrections, validationErr)
+	return summary, nil
+}
 
+// extractTagsAndCategory uses the provider to extract relevant tags and categorize the article.
+// The response is requested as JSON, validated, and re-requested with a corrective prompt
+// when it does not match the allowed categories or tag rules.
+func (a *Analyzer) extractTagsAndCategory(ctx context.Context, article models.Article) ([]string, string, error) {
+	basePrompt, err := a.prompts.render(promptTags, PromptData{
+		Title:       article.Title,
+		Description: article.Description,
+		Categories:  Categories,
+	})
 	if err != nil {
 		return nil, "", err
 	}
 
-	// Parse response
-	lines := strings.Split(responseText, "\n")
-	var category string
-	var tags []string
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "Category:") {
-			category = strings.TrimSpace(strings.TrimPrefix(line, "Category:"))
-		} else if strings.HasPrefix(line, "Tags:") {
-			tagStr := strings.TrimSpace(strings.TrimPrefix(line, "Tags:"))
-			tagStr = strings.Trim(tagStr, "[]")
-			for _, tag := range strings.Split(tagStr, ",") {
-				tag = strings.TrimSpace(tag)
-				if tag != "" {
-					tags = append(tags, tag)
-				}
-			}
+	if text, ok := a.cacheLookup(TaskTags, article, basePrompt); ok {
+		if tags, category, err := parseTagsResponse(text); err == nil {
+			return tags, category, nil
 		}
 	}
 
-	if category == "" {
-		category = "General"
-	}
-	if len(tags) == 0 {
-		tags = []string{"tech"}
-	}
-
-	return tags, category, nil
-}
-
-// selectWithDiversity selects top N articles while ensuring category diversity and topic uniqueness
-func (a *Analyzer) selectWithDiversity(analyzed []models.AnalyzedArticle, topN int) []models.AnalyzedArticle {
-	selected := make([]models.AnalyzedArticle, 0, topN)
-	categoryCount := make(map[string]int)
-	const maxPerCategory = 2
+	prompt := basePrompt
+	var validationErr error
+	for attempt := 0; attempt <= maxTagCorrections; attempt++ {
+		if attempt > 0 {
+			a.mu.Lock()
+			a.tagStats.corrections++
+			a.mu.Unlock()
+			log.Printf("  ↻ Invalid tags for '%s' (%v), retrying with corrective prompt (%d/%d)", article.Title, validationErr, attempt, maxTagCorrections)
+		}
 
-	// Keep track of selected article topics for duplicate detection
-	selectedTopics := make([]string, 0, topN)
+		var responseText string
+		err := a.retry.Do(ctx, func() error {
+			text, err := a.generateStructured(ctx, TaskTags, prompt, tagsSchema)
+			if err != nil {
+				return fmt.Errorf("failed to extract tags: %w", err)
+			}
 
-	for _, article := range analyzed {
-		if len(selected) >= topN {
-			break
+			responseText = text
+			return nil
+		})
+		if err != nil {
+			return nil, "", err
 		}
 
-		// Check category limit
-		if categoryCount[article.Category] >= maxPerCategory {
-			log.Printf("  ⊘ Skipping '%s' - category '%s' limit reached (%d/%d)",
-				article.Title, article.Category, categoryCount[article.Category], maxPerCategory)
-			continue
+		tags, category, err := parseTagsResponse(responseText)
+		if err == nil {
+			a.cacheStore(TaskTags, article, responseText)
+			return tags, category, nil
 		}
 
-		// Check for duplicate topics
-		if a.isDuplicateTopic(article.Title, selectedTopics) {
-			log.Printf("  ⊘ Skipping '%s' - similar topic already selected", article.Title)
-			continue
+		validationErr = err
+		prompt, err = a.correctiveTagsPrompt(basePrompt, responseText, err)
+		if err != nil {
+			return nil, "", err
 		}
-
-		// Add to selected
-		selected = append(selected, article)
-		categoryCount[article.Category]++
-		selectedTopics = append(selectedTopics, article.Title)
 	}
 
-	return selected
+	a.mu.Lock()
+	a.tagStats.invalid++
+	a.mu.Unlock()
+	return nil, "", fmt.Errorf("invalid tags response after %d corrective attempts: %w", maxTagCorrections, validationErr)
 }
 
 // isDuplicateTopic checks if an article title is too similar to already selected topics
@@ -0,0 +1,111 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+// maxBatchDescriptionLength caps each description in a batched prompt so large
+// batches stay within the model's context window
+const maxBatchDescriptionLength = 500
+
+// batchScoreSchema describes the JSON array expected from a batched scoring prompt
+var batchScoreSchema = &Schema{
+	Type: "array",
+	Items: &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"index": {Type: "integer", Description: "Index of the article in the list"},
+			"score": {Type: "number", Description: "Relevance score between 0 and 10"},
+		},
+		Required: []string{"index", "score"},
+	},
+}
+
+// batchScore is a single entry of a batched scoring response
+type batchScore struct {
+	Index *int     `json:"index"`
+	Score *float64 `json:"score"`
+}
+
+// scoreBatch scores several articles with a single prompt. The returned map is keyed by
+// the article's position in the batch and only contains entries that passed validation.
+func (a *Analyzer) scoreBatch(ctx context.Context, articles []models.Article) (map[int]float64, error) {
+	list := make([]PromptArticle, len(articles))
+	for i, article := range articles {
+		list[i] = PromptArticle{
+			Index:       i,
+			Title:       flattenText(article.Title, 0),
+			Description: flattenText(article.Description, maxBatchDescriptionLength),
+		}
+	}
+
+	prompt, err := a.prompts.render(promptScoreBatch, PromptData{Articles: list})
+	if err != nil {
+		return nil, err
+	}
+
+	var responseText string
+	err = a.retry.Do(ctx, func() error {
+		text, err := a.generateStructured(ctx, TaskScoreBatch, prompt, batchScoreSchema)
+		if err != nil {
+			return fmt.Errorf("failed to score batch: %w", err)
+		}
+
+		responseText = text
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []batchScore
+	if err := json.Unmarshal([]byte(extractJSON(responseText)), &entries); err != nil {
+		return nil, fmt.Errorf("invalid batch response: %w", err)
+	}
+
+	scores := make(map[int]float64, len(articles))
+	conflicting := make(map[int]bool)
+	for _, entry := range entries {
+		if entry.Index == nil || entry.Score == nil {
+			continue
+		}
+		index, score := *entry.Index, *entry.Score
+		if index < 0 || index >= len(articles) || score < 0 || score > 10 {
+			continue
+		}
+		if _, seen := scores[index]; seen || conflicting[index] {
+			// Duplicate entries for one article: trust none of them
+			delete(scores, index)
+			conflicting[index] = true
+			continue
+		}
+		scores[index] = score
+	}
+
+	return scores, nil
+}
+
+// flattenText collapses whitespace onto one line and truncates to maxLen runes (0 for no limit)
+func flattenText(s string, maxLen int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if runes := []rune(s); maxLen > 0 && len(runes) > maxLen {
+		s = string(runes[:maxLen]) + "…"
+	}
+	return s
+}
+
+// extractJSON strips Markdown code fences that some models wrap around JSON output
+func extractJSON(text string) string {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```json")
+		text = strings.TrimPrefix(text, "```")
+		text = strings.TrimSuffix(text, "```")
+	}
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,209 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+// stubProvider answers chosen tasks with canned responses or errors and the rest with
+// the fake provider, counting the requests made for each task
+type stubProvider struct {
+	*FakeProvider
+	responses map[Task]string
+	errs      map[Task]error
+
+	mu    sync.Mutex
+	calls map[Task]int
+}
+
+func newStubProvider() *stubProvider {
+	return &stubProvider{
+		FakeProvider: NewFakeProvider(),
+		responses:    make(map[Task]string),
+		errs:         make(map[Task]error),
+		calls:        make(map[Task]int),
+	}
+}
+
+func (p *stubProvider) Generate(ctx context.Context, req Request) (*Response, error) {
+	p.mu.Lock()
+	p.calls[req.Task]++
+	p.mu.Unlock()
+
+	if err, ok := p.errs[req.Task]; ok {
+		return nil, err
+	}
+	if text, ok := p.responses[req.Task]; ok {
+		return &Response{Text: text}, nil
+	}
+	return p.FakeProvider.Generate(ctx, req)
+}
+
+// callsFor returns the number of requests made for task
+func (p *stubProvider) callsFor(task Task) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls[task]
+}
+
+// batchArticles returns n distinct articles
+func batchArticles(n int) []models.Article {
+	articles := make([]models.Article, n)
+	for i := range articles {
+		articles[i] = models.Article{
+			Title:       fmt.Sprintf("Article number %d", i),
+			Description: fmt.Sprintf("Description of article %d", i),
+			Link:        fmt.Sprintf("https://example.com/%d", i),
+		}
+	}
+	return articles
+}
+
+func TestScoreBatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     map[int]float64
+		wantErr  bool
+	}{
+		{
+			name:     "every article scored",
+			response: `[{"index": 0, "score": 7.5}, {"index": 1, "score": 3}, {"index": 2, "score": 10}]`,
+			want:     map[int]float64{0: 7.5, 1: 3, 2: 10},
+		},
+		{
+			name:     "code fence stripped",
+			response: "```json\n[{\"index\": 0, \"score\": 1}, {\"index\": 2, \"score\": 0}]\n```",
+			want:     map[int]float64{0: 1, 2: 0},
+		},
+		{
+			name:     "duplicate index trusted for neither entry",
+			response: `[{"index": 0, "score": 7}, {"index": 1, "score": 4}, {"index": 1, "score": 6}, {"index": 1, "score": 4}]`,
+			want:     map[int]float64{0: 7},
+		},
+		{
+			name:     "entries without index or score skipped",
+			response: `[{"score": 7}, {"index": 1}, {"index": 2, "score": 5}]`,
+			want:     map[int]float64{2: 5},
+		},
+		{
+			name:     "index outside the batch skipped",
+			response: `[{"index": -1, "score": 7}, {"index": 3, "score": 7}, {"index": 0, "score": 2}]`,
+			want:     map[int]float64{0: 2},
+		},
+		{
+			name:     "score outside 0-10 skipped",
+			response: `[{"index": 0, "score": -0.5}, {"index": 1, "score": 10.5}, {"index": 2, "score": 9}]`,
+			want:     map[int]float64{2: 9},
+		},
+		{
+			name:     "empty array",
+			response: `[]`,
+			want:     map[int]float64{},
+		},
+		{
+			name:     "malformed JSON",
+			response: `[{"index": 0, "score": 7}`,
+			wantErr:  true,
+		},
+		{
+			name:     "object instead of array",
+			response: `{"index": 0, "score": 7}`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newStubProvider()
+			provider.responses[TaskScoreBatch] = tt.response
+			analyzer := NewAnalyzer(provider, &models.Config{})
+
+			got, err := analyzer.scoreBatch(context.Background(), batchArticles(3))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("scoreBatch returned %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("scoreBatch: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("scoreBatch = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreArticlesFallback(t *testing.T) {
+	tests := []struct {
+		name          string
+		response      string
+		err           error
+		wantBatches   int
+		wantFallbacks int
+		wantScores    map[int]float64 // Scores expected from the batch; the rest come from per-article scoring
+	}{
+		{
+			name:        "batches cover every article",
+			response:    `[{"index": 0, "score": 8}, {"index": 1, "score": 6}]`,
+			wantBatches: 2, wantFallbacks: 0,
+			wantScores: map[int]float64{0: 8, 1: 6, 2: 8, 3: 6},
+		},
+		{
+			name:        "articles missing from a batch scored individually",
+			response:    `[{"index": 1, "score": 6}]`,
+			wantBatches: 2, wantFallbacks: 2,
+			wantScores: map[int]float64{1: 6, 3: 6},
+		},
+		{
+			name:        "invalid batch falls back for all its articles",
+			response:    `not json`,
+			wantBatches: 2, wantFallbacks: 4,
+			wantScores: map[int]float64{},
+		},
+		{
+			name:        "failed batch falls back for all its articles",
+			err:         errors.New("model unavailable"),
+			wantBatches: 2, wantFallbacks: 4,
+			wantScores: map[int]float64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newStubProvider()
+			if tt.err != nil {
+				provider.errs[TaskScoreBatch] = tt.err
+			} else {
+				provider.responses[TaskScoreBatch] = tt.response
+			}
+			analyzer := NewAnalyzer(provider, &models.Config{ScoreBatchSize: 2})
+
+			articles := batchArticles(4)
+			analyzed, scored := analyzer.scoreArticles(context.Background(), articles)
+
+			if got := provider.callsFor(TaskScoreBatch); got != tt.wantBatches {
+				t.Errorf("batch requests = %d, want %d", got, tt.wantBatches)
+			}
+			if got := provider.callsFor(TaskScore); got != tt.wantFallbacks {
+				t.Errorf("per-article requests = %d, want %d", got, tt.wantFallbacks)
+			}
+			for i, article := range analyzed {
+				if !scored[i] {
+					t.Errorf("article %d was not scored", i)
+				}
+				if want, ok := tt.wantScores[i]; ok && article.RelevanceScore != want {
+					t.Errorf("article %d scored %v, want %v from its batch", i, article.RelevanceScore, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package ai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+// Cache stores LLM results between runs
+type Cache interface {
+	Get(key models.LLMCacheKey) (string, bool, error)
+	Put(key models.LLMCacheKey, response string) error
+}
+
+// cacheStats counts cache usage for a single run
+type cacheStats struct {
+	hits        int
+	misses      int
+	tokensSaved int // Estimated prompt and response tokens not sent to the provider
+}
+
+// SetCache enables result caching for scoring, tagging and summarizing
+func (a *Analyzer) SetCache(cache Cache) {
+	a.cache = cache
+}
+
+// cacheKey builds the cache key for an article and task
+func (a *Analyzer) cacheKey(task Task, article models.Article) models.LLMCacheKey {
+	hash := sha256.Sum256([]byte(article.Title + "\n" + article.Description + "\n" + article.Content))
+	return models.LLMCacheKey{
+		URL:           article.Link,
+		ContentHash:   hex.EncodeToString(hash[:]),
+		Task:          string(task),
+		PromptVersion: a.prompts.cacheVersion(task),
+		Model:         a.provider.Name() + "/" + a.provider.Model(),
+	}
+}
+
+// cacheLookup returns the cached response for the article and task, if any.
+// prompt is only used to estimate the tokens a hit saves.
+func (a *Analyzer) cacheLookup(task Task, article models.Article, prompt string) (string, bool) {
+	if a.cache == nil {
+		return "", false
+	}
+
+	response, ok, err := a.cache.Get(a.cacheKey(task, article))
+	if err != nil {
+		log.Printf("  Warning: Cache lookup failed for '%s': %v", article.Title, err)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !ok {
+		a.cacheStats.misses++
+		return "", false
+	}
+
+	a.cacheStats.hits++
+	a.cacheStats.tokensSaved += estimateTokens(prompt) + estimateTokens(response)
+	return response, true
+}
+
+// cacheStore saves a validated response for the article and task
+func (a *Analyzer) cacheStore(task Task, article models.Article, response string) {
+	if a.cache == nil {
+		return
+	}
+
+	if err := a.cache.Put(a.cacheKey(task, article), response); err != nil {
+		log.Printf("  Warning: Failed to cache %s result for '%s': %v", task, article.Title, err)
+	}
+}
+
+// outputTokenAllowance is the response size assumed when estimating a request's tokens
+const outputTokenAllowance = 100
+
+// estimateTokens roughly approximates a token count as one token per four characters
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
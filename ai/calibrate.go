@@ -0,0 +1,130 @@
+package ai
+
+import (
+	"log"
+	"math"
+	"sort"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+// Score calibration methods
+const (
+	CalibrationPercentile = "percentile" // 10 × the share of reference scores below the raw score
+	CalibrationZScore     = "zscore"     // 5 + 2 × standard deviations from the reference mean, clamped to 0-10
+	CalibrationOff        = "off"        // Use raw scores as they are
+)
+
+// minCalibrationSamples is the smallest reference distribution scores are calibrated
+// against; with fewer scores the raw values are kept
+const minCalibrationSamples = 20
+
+// ScoreHistory stores raw relevance scores of earlier runs, by model and scoring prompt
+// version, so each run's scores can be calibrated against a stable reference
+type ScoreHistory interface {
+	Scores(model, promptVersion string) (map[string]float64, error) // By article URL
+	Add(model, promptVersion string, scores map[string]float64) error
+}
+
+// SetScoreHistory enables calibrating scores against earlier runs' scores. Without a
+// history, each run is calibrated against its own scores.
+func (a *Analyzer) SetScoreHistory(history ScoreHistory) {
+	a.history = history
+}
+
+// ReadOnlyHistory wraps a score history so a run calibrates against it without adding
+// its own scores, which keeps dry runs from shifting the reference of later runs
+func ReadOnlyHistory(history ScoreHistory) ScoreHistory {
+	return readOnlyHistory{history}
+}
+
+// readOnlyHistory is a ScoreHistory whose Add does nothing
+type readOnlyHistory struct {
+	ScoreHistory
+}
+
+func (readOnlyHistory) Add(model, promptVersion string, scores map[string]float64) error {
+	return nil
+}
+
+// calibrateScores keeps each article's raw score in RawScore and replaces RelevanceScore
+// with its calibrated value on a 0-10 scale. The reference distribution is the run's
+// scored articles plus the history for the current model and scoring prompts, so scores
+// stay comparable across days and model changes. Articles that failed scoring keep 0.
+func (a *Analyzer) calibrateScores(analyzed []models.AnalyzedArticle, scored []bool) {
+	current := make(map[string]float64, len(analyzed))
+	for i := range analyzed {
+		analyzed[i].RawScore = analyzed[i].RelevanceScore
+		if scored[i] {
+			current[analyzed[i].Link] = analyzed[i].RelevanceScore
+		}
+	}
+	if a.calibration == CalibrationOff || len(current) == 0 {
+		return
+	}
+
+	reference := make([]float64, 0, len(current))
+	for _, score := range current {
+		reference = append(reference, score)
+	}
+	if a.history != nil {
+		model, version := a.provider.Model(), a.prompts.cacheVersion(TaskScore)
+		past, err := a.history.Scores(model, version)
+		if err != nil {
+			log.Printf("  ✗ Failed to load score history, calibrating against this run only: %v", err)
+		}
+		for link, score := range past {
+			if _, ok := current[link]; !ok {
+				reference = append(reference, score)
+			}
+		}
+		if err := a.history.Add(model, version, current); err != nil {
+			log.Printf("  ✗ Failed to save scores to history: %v", err)
+		}
+	}
+
+	if len(reference) < minCalibrationSamples {
+		log.Printf("Score calibration: only %d reference scores (need %d), keeping raw scores", len(reference), minCalibrationSamples)
+		return
+	}
+	sort.Float64s(reference)
+
+	var mean, stddev float64
+	for _, score := range reference {
+		mean += score
+	}
+	mean /= float64(len(reference))
+	for _, score := range reference {
+		stddev += (score - mean) * (score - mean)
+	}
+	stddev = math.Sqrt(stddev / float64(len(reference)))
+	if a.calibration == CalibrationZScore && stddev == 0 {
+		log.Printf("Score calibration: all %d reference scores are %.1f, keeping raw scores", len(reference), mean)
+		return
+	}
+
+	var rawTotal, calibratedTotal float64
+	for i := range analyzed {
+		if !scored[i] {
+			continue
+		}
+		raw := analyzed[i].RawScore
+		if a.calibration == CalibrationZScore {
+			analyzed[i].RelevanceScore = math.Max(0, math.Min(10, 5+2*(raw-mean)/stddev))
+		} else {
+			analyzed[i].RelevanceScore = percentileScore(reference, raw)
+		}
+		rawTotal += raw
+		calibratedTotal += analyzed[i].RelevanceScore
+	}
+	log.Printf("Score calibration (%s) against %d reference scores (mean %.2f, sd %.2f): run mean %.2f → %.2f",
+		a.calibration, len(reference), mean, stddev, rawTotal/float64(len(current)), calibratedTotal/float64(len(current)))
+}
+
+// percentileScore returns the mid-rank percentile of score within the sorted reference,
+// scaled to 0-10: scores tied with others count half below them
+func percentileScore(sorted []float64, score float64) float64 {
+	below := sort.SearchFloat64s(sorted, score)
+	above := sort.Search(len(sorted), func(i int) bool { return sorted[i] > score })
+	return 10 * (float64(below) + float64(above-below)/2) / float64(len(sorted))
+}
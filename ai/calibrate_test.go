@@ -0,0 +1,214 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+func TestPercentileScore(t *testing.T) {
+	tests := []struct {
+		name      string
+		reference []float64
+		score     float64
+		want      float64
+	}{
+		{name: "below every score", reference: []float64{5, 6, 7, 8}, score: 4, want: 0},
+		{name: "above every score", reference: []float64{5, 6, 7, 8}, score: 9, want: 10},
+		{name: "lowest score counts half of itself", reference: []float64{5, 6, 7, 8}, score: 5, want: 1.25},
+		{name: "between scores", reference: []float64{5, 6, 7, 8}, score: 6.5, want: 5},
+		{name: "ties count half below", reference: []float64{5, 7, 7, 7, 9}, score: 7, want: 5},
+		{name: "all scores tied", reference: []float64{7, 7, 7, 7}, score: 7, want: 5},
+		{name: "single score", reference: []float64{7}, score: 7, want: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentileScore(tt.reference, tt.score); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("percentileScore(%v, %v) = %v, want %v", tt.reference, tt.score, got, tt.want)
+			}
+		})
+	}
+}
+
+// memoryHistory is a ScoreHistory holding one model and prompt version's scores
+type memoryHistory struct {
+	scores map[string]float64
+	err    error
+	added  map[string]float64 // Scores of the last Add
+}
+
+func (h *memoryHistory) Scores(model, promptVersion string) (map[string]float64, error) {
+	return h.scores, h.err
+}
+
+func (h *memoryHistory) Add(model, promptVersion string, scores map[string]float64) error {
+	h.added = scores
+	return nil
+}
+
+// historyOf returns the given history scores, keyed by made-up links
+func historyOf(scores ...float64) map[string]float64 {
+	history := make(map[string]float64, len(scores))
+	for i, score := range scores {
+		history[fmt.Sprintf("https://example.com/past/%d", i)] = score
+	}
+	return history
+}
+
+// pastScores returns n history scores spread evenly over 0-10
+func pastScores(n int) map[string]float64 {
+	scores := make([]float64, n)
+	for i := range scores {
+		scores[i] = 10 * float64(i) / float64(n-1)
+	}
+	return historyOf(scores...)
+}
+
+// withScore adds a score for link to history
+func withScore(history map[string]float64, link string, score float64) map[string]float64 {
+	history[link] = score
+	return history
+}
+
+func TestCalibrateScores(t *testing.T) {
+	tests := []struct {
+		name        string
+		calibration string
+		history     *memoryHistory // nil calibrates against the run alone
+		raw         []float64
+		scored      []bool
+		want        []float64
+	}{
+		{
+			name:        "off keeps raw scores",
+			calibration: CalibrationOff,
+			history:     &memoryHistory{scores: pastScores(40)},
+			raw:         []float64{8, 7.5},
+			scored:      []bool{true, true},
+			want:        []float64{8, 7.5},
+		},
+		{
+			name:        "empty history keeps raw scores of a small run",
+			calibration: CalibrationPercentile,
+			history:     &memoryHistory{},
+			raw:         []float64{8, 7.5, 7},
+			scored:      []bool{true, true, true},
+			want:        []float64{8, 7.5, 7},
+		},
+		{
+			name:        "failed history keeps raw scores of a small run",
+			calibration: CalibrationPercentile,
+			history:     &memoryHistory{err: errors.New("database is down")},
+			raw:         []float64{8, 7.5, 7},
+			scored:      []bool{true, true, true},
+			want:        []float64{8, 7.5, 7},
+		},
+		{
+			name:        "no history calibrates a large run against itself",
+			calibration: CalibrationPercentile,
+			raw:         []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20},
+			scored:      []bool{true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true},
+			want:        []float64{0.25, 0.75, 1.25, 1.75, 2.25, 2.75, 3.25, 3.75, 4.25, 4.75, 5.25, 5.75, 6.25, 6.75, 7.25, 7.75, 8.25, 8.75, 9.25, 9.75},
+		},
+		{
+			name:        "percentile against history",
+			calibration: CalibrationPercentile,
+			history:     &memoryHistory{scores: historyOf(0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17)},
+			raw:         []float64{20, 0.5},
+			scored:      []bool{true, true},
+			want:        []float64{9.75, 0.75},
+		},
+		{
+			name:        "history copy of a run article is not counted twice",
+			calibration: CalibrationPercentile,
+			history:     &memoryHistory{scores: withScore(historyOf(0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17), "https://example.com/run/0", 3)},
+			raw:         []float64{20, 0.5},
+			scored:      []bool{true, true},
+			want:        []float64{9.75, 0.75},
+		},
+		{
+			name:        "unscored articles keep 0",
+			calibration: CalibrationPercentile,
+			history:     &memoryHistory{scores: pastScores(40)},
+			raw:         []float64{10, 0},
+			scored:      []bool{true, false},
+			want:        []float64{400.0 / 41, 0},
+		},
+		{
+			name:        "zscore clamps to the scale",
+			calibration: CalibrationZScore,
+			history:     &memoryHistory{scores: pastScores(40)},
+			raw:         []float64{40, -30},
+			scored:      []bool{true, true},
+			want:        []float64{10, 0},
+		},
+		{
+			name:        "zscore keeps raw scores when all are tied",
+			calibration: CalibrationZScore,
+			history:     &memoryHistory{scores: historyOf(7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7)},
+			raw:         []float64{7, 7},
+			scored:      []bool{true, true},
+			want:        []float64{7, 7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewAnalyzer(NewFakeProvider(), &models.Config{ScoreCalibration: tt.calibration})
+			if tt.history != nil {
+				analyzer.SetScoreHistory(tt.history)
+			}
+
+			analyzed := make([]models.AnalyzedArticle, len(tt.raw))
+			for i, score := range tt.raw {
+				analyzed[i] = models.AnalyzedArticle{
+					Article:        models.Article{Link: fmt.Sprintf("https://example.com/run/%d", i)},
+					RelevanceScore: score,
+				}
+			}
+			analyzer.calibrateScores(analyzed, tt.scored)
+
+			for i, article := range analyzed {
+				if article.RawScore != tt.raw[i] {
+					t.Errorf("article %d raw score = %v, want %v", i, article.RawScore, tt.raw[i])
+				}
+				if math.Abs(article.RelevanceScore-tt.want[i]) > 1e-9 {
+					t.Errorf("article %d calibrated to %v, want %v", i, article.RelevanceScore, tt.want[i])
+				}
+			}
+
+			// Only the run's scored articles join the history
+			if tt.history == nil || tt.calibration == CalibrationOff {
+				return
+			}
+			for i, article := range analyzed {
+				if _, ok := tt.history.added[article.Link]; ok != tt.scored[i] {
+					t.Errorf("article %d added to history = %v, want %v", i, ok, tt.scored[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReadOnlyHistory(t *testing.T) {
+	history := &memoryHistory{scores: pastScores(40)}
+	analyzer := NewAnalyzer(NewFakeProvider(), &models.Config{ScoreCalibration: CalibrationPercentile})
+	analyzer.SetScoreHistory(ReadOnlyHistory(history))
+
+	analyzed := []models.AnalyzedArticle{{
+		Article:        models.Article{Link: "https://example.com/run/0"},
+		RelevanceScore: 10,
+	}}
+	analyzer.calibrateScores(analyzed, []bool{true})
+
+	if want := 400.0 / 41; math.Abs(analyzed[0].RelevanceScore-want) > 1e-9 {
+		t.Errorf("calibrated to %v, want %v against the wrapped history", analyzed[0].RelevanceScore, want)
+	}
+	if history.added != nil {
+		t.Errorf("read-only history added scores %v", history.added)
+	}
+}
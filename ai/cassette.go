@@ -0,0 +1,277 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+// ErrUnrecordedPrompt is returned by ReplayProvider for prompts missing from its cassette
+var ErrUnrecordedPrompt = errors.New("prompt not found in cassette")
+
+// embedTask marks recorded embeddings, whose prompt is the embedded text
+const embedTask Task = "embed"
+
+// cassetteEntry is one line of a cassette file: a recorded prompt/response pair, a
+// recorded embedding, or the run's input articles
+type cassetteEntry struct {
+	Task      Task             `json:"task,omitempty"`
+	Prompt    string           `json:"prompt,omitempty"`
+	Response  string           `json:"response,omitempty"`
+	Embedding []float32        `json:"embedding,omitempty"`
+	Articles  []models.Article `json:"articles,omitempty"`
+}
+
+// Cassette holds recorded provider responses keyed by task and prompt, and optionally
+// the articles the recorded run analyzed
+type Cassette struct {
+	mu         sync.Mutex
+	entries    map[cassetteKey]string
+	embeddings map[string][]float32
+	articles   []models.Article
+}
+
+type cassetteKey struct {
+	task   Task
+	prompt string
+}
+
+// NewCassette creates an empty cassette for recording
+func NewCassette() *Cassette {
+	return &Cassette{
+		entries:    make(map[cassetteKey]string),
+		embeddings: make(map[string][]float32),
+	}
+}
+
+// LoadCassette reads a cassette from a JSON Lines file
+func LoadCassette(path string) (*Cassette, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	cassette := NewCassette()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry cassetteEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		switch {
+		case entry.Articles != nil:
+			cassette.articles = entry.Articles
+		case entry.Task == embedTask:
+			cassette.embeddings[entry.Prompt] = entry.Embedding
+		default:
+			cassette.entries[cassetteKey{entry.Task, entry.Prompt}] = entry.Response
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cassette, nil
+}
+
+// Save writes the cassette as JSON Lines, sorted so re-recordings diff cleanly
+func (c *Cassette) Save(path string) error {
+	c.mu.Lock()
+	entries := make([]cassetteEntry, 0, len(c.entries)+len(c.embeddings))
+	for key, response := range c.entries {
+		entries = append(entries, cassetteEntry{Task: key.task, Prompt: key.prompt, Response: response})
+	}
+	for text, embedding := range c.embeddings {
+		entries = append(entries, cassetteEntry{Task: embedTask, Prompt: text, Embedding: embedding})
+	}
+	articles := c.articles
+	c.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Task != entries[j].Task {
+			return entries[i].Task < entries[j].Task
+		}
+		return entries[i].Prompt < entries[j].Prompt
+	})
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	if articles != nil {
+		entries = append([]cassetteEntry{{Articles: articles}}, entries...)
+	}
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Len returns the number of recorded responses and embeddings
+func (c *Cassette) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries) + len(c.embeddings)
+}
+
+// SetArticles records the articles a run analyzes so a replay can use the same input
+func (c *Cassette) SetArticles(articles []models.Article) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.articles = articles
+}
+
+// Articles returns the recorded input articles, or nil if none were recorded
+func (c *Cassette) Articles() []models.Article {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.articles
+}
+
+func (c *Cassette) get(task Task, prompt string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	response, ok := c.entries[cassetteKey{task, prompt}]
+	return response, ok
+}
+
+func (c *Cassette) put(task Task, prompt, response string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[cassetteKey{task, prompt}] = response
+}
+
+// RecordingProvider passes requests to another provider and records every response
+type RecordingProvider struct {
+	Provider
+	cassette *Cassette
+}
+
+// NewRecordingProvider records the responses of provider into cassette
+func NewRecordingProvider(provider Provider, cassette *Cassette) *RecordingProvider {
+	return &RecordingProvider{Provider: provider, cassette: cassette}
+}
+
+// Generate forwards the request and records a successful response
+func (p *RecordingProvider) Generate(ctx context.Context, req Request) (*Response, error) {
+	response, err := p.Provider.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	p.cassette.put(req.Task, req.Prompt, response.Text)
+	return response, nil
+}
+
+// Embed forwards the texts and records each returned embedding
+func (p *RecordingProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors, err := p.Provider.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	p.cassette.mu.Lock()
+	defer p.cassette.mu.Unlock()
+	for i, text := range texts {
+		p.cassette.embeddings[text] = vectors[i]
+	}
+	return vectors, nil
+}
+
+// ReplayProvider answers strictly from a recorded cassette, without network access.
+// The first unrecorded prompt is kept as the provider's error so the whole run fails
+// rather than silently falling back.
+type ReplayProvider struct {
+	cassette *Cassette
+
+	mu  sync.Mutex
+	err error
+}
+
+// NewReplayProvider creates a provider that replays cassette
+func NewReplayProvider(cassette *Cassette) *ReplayProvider {
+	return &ReplayProvider{cassette: cassette}
+}
+
+// Name returns the provider identifier
+func (p *ReplayProvider) Name() string {
+	return "replay"
+}
+
+// Model returns the replay model name
+func (p *ReplayProvider) Model() string {
+	return "cassette"
+}
+
+// Generate returns the recorded response for the request, or ErrUnrecordedPrompt
+func (p *ReplayProvider) Generate(ctx context.Context, req Request) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	response, ok := p.cassette.get(req.Task, req.Prompt)
+	if !ok {
+		return nil, p.fail(fmt.Errorf("%w (task %s): %.80q", ErrUnrecordedPrompt, req.Task, req.Prompt))
+	}
+	return &Response{Text: response}, nil
+}
+
+// Embed returns the recorded embedding of each text, or ErrUnrecordedPrompt
+func (p *ReplayProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p.cassette.mu.Lock()
+	defer p.cassette.mu.Unlock()
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector, ok := p.cassette.embeddings[text]
+		if !ok {
+			return nil, p.fail(fmt.Errorf("%w (task %s): %.80q", ErrUnrecordedPrompt, embedTask, text))
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+// Err returns the first unrecorded prompt error, if any
+func (p *ReplayProvider) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// fail remembers the first replay error and returns err
+func (p *ReplayProvider) fail(err error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err == nil {
+		p.err = err
+	}
+	return err
+}
+
+// Close is a no-op for the replay provider
+func (p *ReplayProvider) Close() error {
+	return nil
+}
@@ -0,0 +1,128 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+var update = flag.Bool("update", false, "re-record the test cassettes with the fake provider")
+
+var selectionCassette = filepath.Join("testdata", "select_and_summarize.jsonl")
+
+// cassetteArticles are the articles recorded in selectionCassette
+func cassetteArticles() []models.Article {
+	published := time.Date(2026, 1, 15, 9, 0, 0, 0, time.UTC)
+	return []models.Article{
+		{Title: "Go 1.26 ships faster generic method calls", Description: "The release trims the dictionary overhead of generic code.", Link: "https://example.com/go-126", Source: "Go Blog", Published: published, Content: "The Go team released Go 1.26 with faster generic method calls."},
+		{Title: "Postgres 19 adds incremental materialized views", Description: "Views now refresh only the rows that changed.", Link: "https://example.com/pg-19", Source: "Postgres News", Published: published, Content: "Postgres 19 can refresh materialized views incrementally."},
+		{Title: "Kubernetes deprecates the legacy scheduler plugins", Description: "Clusters must migrate before the next minor release.", Link: "https://example.com/k8s-scheduler", Source: "CNCF", Published: published, Content: "Kubernetes deprecated its legacy scheduler plugins."},
+		{Title: "A practical guide to Rust lifetimes", Description: "Reading lifetime errors without guessing.", Link: "https://example.com/rust-lifetimes", Source: "Rust Weekly", Published: published, Content: "Lifetimes describe how long references are valid."},
+		{Title: "Browser vendors agree on a CSS masonry layout", Description: "Grid gains a masonry mode across engines.", Link: "https://example.com/css-masonry", Source: "Web Platform", Published: published, Content: "CSS grid gains a masonry layout mode."},
+		{Title: "OpenSSL patches a certificate parsing flaw", Description: "A crafted certificate could crash TLS servers.", Link: "https://example.com/openssl", Source: "Security Digest", Published: published, Content: "OpenSSL fixed a certificate parsing flaw."},
+	}
+}
+
+// newReplayAnalyzer creates an analyzer for provider with default settings
+func newReplayAnalyzer(provider Provider) *Analyzer {
+	return NewAnalyzer(provider, &models.Config{AIConcurrency: 2})
+}
+
+// recordSelectionCassette records a SelectAndSummarize run of the fake provider
+func recordSelectionCassette(t *testing.T) {
+	t.Helper()
+	cassette := NewCassette()
+	cassette.SetArticles(cassetteArticles())
+	analyzer := newReplayAnalyzer(NewRecordingProvider(NewFakeProvider(), cassette))
+	if _, err := analyzer.SelectAndSummarize(context.Background(), cassetteArticles(), 4); err != nil {
+		t.Fatalf("recording: %v", err)
+	}
+	if err := cassette.Save(selectionCassette); err != nil {
+		t.Fatalf("saving cassette: %v", err)
+	}
+}
+
+func TestSelectAndSummarizeReplay(t *testing.T) {
+	if *update {
+		recordSelectionCassette(t)
+	}
+
+	cassette, err := LoadCassette(selectionCassette)
+	if err != nil {
+		t.Fatalf("LoadCassette: %v", err)
+	}
+	articles := cassette.Articles()
+	if len(articles) != len(cassetteArticles()) {
+		t.Fatalf("cassette has %d articles, want %d", len(articles), len(cassetteArticles()))
+	}
+
+	replay := func() []models.AnalyzedArticle {
+		provider := NewReplayProvider(cassette)
+		selected, err := newReplayAnalyzer(provider).SelectAndSummarize(context.Background(), articles, 4)
+		if err != nil {
+			t.Fatalf("SelectAndSummarize: %v", err)
+		}
+		if err := provider.Err(); err != nil {
+			t.Fatalf("replay missed a prompt: %v", err)
+		}
+		return selected
+	}
+
+	// The recorded scores put two articles each in DevOps and Cloud, the per-category cap
+	want := []string{
+		"https://example.com/css-masonry",
+		"https://example.com/rust-lifetimes",
+		"https://example.com/openssl",
+		"https://example.com/pg-19",
+	}
+	first := replay()
+	if len(first) != len(want) {
+		t.Fatalf("selected %d articles, want %d", len(first), len(want))
+	}
+	for i, article := range first {
+		if article.Link != want[i] {
+			t.Errorf("article %d = %s, want %s", i, article.Link, want[i])
+		}
+		if !article.Selected {
+			t.Errorf("article %d (%s) is not marked selected", i, article.Title)
+		}
+		if article.Summary == "" || article.Summary == "Summary unavailable." {
+			t.Errorf("article %d (%s) has no summary: %q", i, article.Title, article.Summary)
+		}
+		if article.Category == "" {
+			t.Errorf("article %d (%s) has no category", i, article.Title)
+		}
+		if i > 0 && article.RelevanceScore > first[i-1].RelevanceScore {
+			t.Errorf("article %d scored %.1f above article %d at %.1f", i, article.RelevanceScore, i-1, first[i-1].RelevanceScore)
+		}
+	}
+
+	// Replaying the same cassette must reproduce the run exactly
+	second := replay()
+	for i := range first {
+		if first[i].Link != second[i].Link || first[i].Summary != second[i].Summary || first[i].RelevanceScore != second[i].RelevanceScore {
+			t.Errorf("replay %d differs: %s %q %.1f, then %s %q %.1f", i,
+				first[i].Link, first[i].Summary, first[i].RelevanceScore,
+				second[i].Link, second[i].Summary, second[i].RelevanceScore)
+		}
+	}
+}
+
+func TestSelectAndSummarizeReplayFailsOnUnrecordedPrompt(t *testing.T) {
+	cassette, err := LoadCassette(selectionCassette)
+	if err != nil {
+		t.Fatalf("LoadCassette: %v", err)
+	}
+
+	articles := cassette.Articles()
+	articles[0].Title = "A headline the cassette never saw"
+	_, err = newReplayAnalyzer(NewReplayProvider(cassette)).SelectAndSummarize(context.Background(), articles, 4)
+	if !errors.Is(err, ErrUnrecordedPrompt) {
+		t.Fatalf("SelectAndSummarize error = %v, want ErrUnrecordedPrompt", err)
+	}
+}
@@ -0,0 +1,31 @@
+package ai
+
+import "github.com/ty-e-boyd/thepaper/models"
+
+// DecisionRecorder receives the decisions the analyzer makes about each article, for
+// explaining a run's selection. Methods may be called concurrently.
+type DecisionRecorder interface {
+	Scored(article models.Article, score float64, cached bool, err error)
+	Merged(article models.AnalyzedArticle, into models.AnalyzedArticle, similarity float64)
+	Tagged(article models.AnalyzedArticle, err error)
+	Selected(article models.AnalyzedArticle, rank int, reason string)
+	Rejected(article models.AnalyzedArticle, rank int, reason string)
+}
+
+// nopRecorder discards all decisions
+type nopRecorder struct{}
+
+func (nopRecorder) Scored(models.Article, float64, bool, error)                    {}
+func (nopRecorder) Merged(models.AnalyzedArticle, models.AnalyzedArticle, float64) {}
+func (nopRecorder) Tagged(models.AnalyzedArticle, error)                           {}
+func (nopRecorder) Selected(models.AnalyzedArticle, int, string)                   {}
+func (nopRecorder) Rejected(models.AnalyzedArticle, int, string)                   {}
+
+// SetDecisionRecorder records scoring, deduplication, tagging and shared selection
+// decisions. Per-user selections made by Personalize are not recorded.
+func (a *Analyzer) SetDecisionRecorder(recorder DecisionRecorder) {
+	if recorder == nil {
+		recorder = nopRecorder{}
+	}
+	a.recorder = recorder
+}
@@ -0,0 +1,134 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+// embeddingBatchSize is the number of texts sent per embedding request
+const embeddingBatchSize = 100
+
+// dedupeStories groups articles covering the same story by embedding similarity. The
+// input must be sorted by score; the highest-scored article of each group is kept and
+// the others are recorded on it as also covering the story.
+func (a *Analyzer) dedupeStories(ctx context.Context, analyzed []models.AnalyzedArticle) ([]models.AnalyzedArticle, error) {
+	vectors, err := a.embedArticles(ctx, analyzed)
+	if err != nil {
+		return nil, err
+	}
+
+	var representatives []models.AnalyzedArticle
+	var repVectors [][]float32
+	for i, article := range analyzed {
+		match := -1
+		bestSimilarity := a.dedupThreshold
+		for r, repVector := range repVectors {
+			if similarity := cosineSimilarity(vectors[i], repVector); similarity >= bestSimilarity {
+				match, bestSimilarity = r, similarity
+			}
+		}
+
+		if match < 0 {
+			representatives = append(representatives, article)
+			repVectors = append(repVectors, vectors[i])
+			continue
+		}
+
+		rep := &representatives[match]
+		rep.AlsoCoveredBy = append(rep.AlsoCoveredBy, models.RelatedArticle{
+			Title:  article.Title,
+			Link:   article.Link,
+			Source: article.Source,
+		})
+		a.recorder.Merged(article, *rep, bestSimilarity)
+		log.Printf("  ≈ '%s' (%s) covers the same story as '%s' (similarity %.2f)", article.Title, article.Source, rep.Title, bestSimilarity)
+	}
+
+	return representatives, nil
+}
+
+// applyCoverageBoost raises the score of stories covered by several sources by
+// coverageBoost per doubling of other sources, capped at 10, and re-sorts the list
+func (a *Analyzer) applyCoverageBoost(stories []models.AnalyzedArticle) {
+	if a.coverageBoost <= 0 {
+		return
+	}
+
+	for i := range stories {
+		others := len(stories[i].OtherSources())
+		if others == 0 {
+			continue
+		}
+
+		boosted := math.Min(10, stories[i].RelevanceScore+a.coverageBoost*math.Log2(1+float64(others)))
+		log.Printf("  ↑ '%s' covered by %d other source(s): %.1f → %.1f", stories[i].Title, others, stories[i].RelevanceScore, boosted)
+		stories[i].RelevanceScore = boosted
+	}
+
+	sort.SliceStable(stories, func(i, j int) bool {
+		return stories[i].RelevanceScore > stories[j].RelevanceScore
+	})
+}
+
+// embedArticles embeds each article's title and description in batches
+func (a *Analyzer) embedArticles(ctx context.Context, analyzed []models.AnalyzedArticle) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(analyzed))
+	for start := 0; start < len(analyzed); start += embeddingBatchSize {
+		end := min(start+embeddingBatchSize, len(analyzed))
+
+		texts := make([]string, 0, end-start)
+		tokens := 0
+		for _, article := range analyzed[start:end] {
+			text := flattenText(article.Title, 0) + ". " + flattenText(article.Description, maxBatchDescriptionLength)
+			texts = append(texts, text)
+			tokens += estimateTokens(text)
+		}
+
+		var batch [][]float32
+		err := a.retry.Do(ctx, func() error {
+			if err := a.limiter.Wait(ctx, tokens); err != nil {
+				return err
+			}
+
+			embeddings, err := a.provider.Embed(ctx, texts)
+			if err != nil {
+				return fmt.Errorf("failed to embed articles: %w", err)
+			}
+			if len(embeddings) != len(texts) {
+				return fmt.Errorf("expected %d embeddings, got %d", len(texts), len(embeddings))
+			}
+
+			batch = embeddings
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+// cosineSimilarity returns the cosine of the angle between two vectors, or 0 if either
+// is empty, zero or the lengths differ
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
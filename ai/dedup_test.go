@@ -0,0 +1,190 @@
+package ai
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float32
+		want float64
+	}{
+		{name: "identical", a: []float32{1, 2, 3}, b: []float32{1, 2, 3}, want: 1},
+		{name: "scaled", a: []float32{1, 2, 3}, b: []float32{2, 4, 6}, want: 1},
+		{name: "orthogonal", a: []float32{1, 0}, b: []float32{0, 1}, want: 0},
+		{name: "opposite", a: []float32{1, -1}, b: []float32{-1, 1}, want: -1},
+		{name: "partial overlap", a: []float32{1, 1, 0}, b: []float32{1, 0, 1}, want: 0.5},
+		{name: "zero vector", a: []float32{0, 0}, b: []float32{1, 1}, want: 0},
+		{name: "different lengths", a: []float32{1, 2}, b: []float32{1, 2, 3}, want: 0},
+		{name: "empty", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cosineSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("cosineSimilarity = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// story returns an analyzed article for the deduplication tests
+func story(title, source string, score float64) models.AnalyzedArticle {
+	return models.AnalyzedArticle{
+		Article:        models.Article{Title: title, Source: source, Link: "https://" + source + ".example.com/" + title},
+		RelevanceScore: score,
+	}
+}
+
+func TestDedupeStories(t *testing.T) {
+	// Sorted by score, as dedupeStories expects
+	articles := []models.AnalyzedArticle{
+		story("Rust compiler release brings faster builds", "alpha", 9),
+		story("Postgres adds vector indexes", "beta", 8),
+		story("Rust compiler release brings faster builds", "gamma", 7),
+		story("Rust compiler release brings faster incremental builds", "delta", 5),
+	}
+
+	tests := []struct {
+		name      string
+		threshold float64
+		want      map[string][]string // Kept article's source to the sources merged into it, in order
+	}{
+		{
+			name:      "same story merged into the best-scored article",
+			threshold: 0.85,
+			want:      map[string][]string{"alpha": {"gamma", "delta"}, "beta": nil},
+		},
+		{
+			name:      "only identical coverage merged at a high threshold",
+			threshold: 0.99,
+			want:      map[string][]string{"alpha": {"gamma"}, "beta": nil, "delta": nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewAnalyzer(NewFakeProvider(), &models.Config{DedupThreshold: tt.threshold})
+
+			stories, err := analyzer.dedupeStories(context.Background(), articles)
+			if err != nil {
+				t.Fatalf("dedupeStories: %v", err)
+			}
+
+			got := make(map[string][]string, len(stories))
+			for _, s := range stories {
+				var merged []string
+				for _, related := range s.AlsoCoveredBy {
+					merged = append(merged, related.Source)
+				}
+				got[s.Source] = merged
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("stories = %v, want %v", got, tt.want)
+			}
+			for i := 1; i < len(stories); i++ {
+				if stories[i].RelevanceScore > stories[i-1].RelevanceScore {
+					t.Errorf("stories out of score order at %d: %v after %v", i, stories[i].RelevanceScore, stories[i-1].RelevanceScore)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyCoverageBoost(t *testing.T) {
+	covered := func(article models.AnalyzedArticle, sources ...string) models.AnalyzedArticle {
+		for _, source := range sources {
+			article.AlsoCoveredBy = append(article.AlsoCoveredBy, models.RelatedArticle{Source: source})
+		}
+		return article
+	}
+
+	tests := []struct {
+		name       string
+		boost      float64
+		stories    []models.AnalyzedArticle
+		wantOrder  []string // By source
+		wantScores []float64
+	}{
+		{
+			name:  "uncovered stories unchanged",
+			boost: 0.5,
+			stories: []models.AnalyzedArticle{
+				story("Lone story", "alpha", 7),
+				story("Wide story", "beta", 6),
+				story("Paired story", "gamma", 5),
+			},
+			wantOrder:  []string{"alpha", "beta", "gamma"},
+			wantScores: []float64{7, 6, 5},
+		},
+		{
+			name:  "boost grows with each doubling of other sources",
+			boost: 0.5,
+			stories: []models.AnalyzedArticle{
+				story("Lone story", "alpha", 7),
+				covered(story("Wide story", "beta", 6), "one", "two", "three"),
+				covered(story("Paired story", "gamma", 5), "one"),
+			},
+			wantOrder:  []string{"alpha", "beta", "gamma"},
+			wantScores: []float64{7, 7, 5.5},
+		},
+		{
+			name:  "repeat and own sources not counted",
+			boost: 1,
+			stories: []models.AnalyzedArticle{
+				story("Lone story", "alpha", 6),
+				covered(story("Repeated story", "beta", 5.5), "beta", "one", "one"),
+			},
+			wantOrder:  []string{"beta", "alpha"},
+			wantScores: []float64{6.5, 6},
+		},
+		{
+			name:  "capped at 10",
+			boost: 2,
+			stories: []models.AnalyzedArticle{
+				covered(story("Big story", "alpha", 9.5), "one", "two", "three"),
+			},
+			wantOrder:  []string{"alpha"},
+			wantScores: []float64{10},
+		},
+		{
+			name:  "no boost configured",
+			boost: 0,
+			stories: []models.AnalyzedArticle{
+				story("Lone story", "alpha", 7),
+				covered(story("Wide story", "beta", 6), "one", "two", "three"),
+			},
+			wantOrder:  []string{"alpha", "beta"},
+			wantScores: []float64{7, 6},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewAnalyzer(NewFakeProvider(), &models.Config{CoverageBoost: tt.boost})
+			analyzer.applyCoverageBoost(tt.stories)
+
+			var order []string
+			var scores []float64
+			for _, s := range tt.stories {
+				order = append(order, s.Source)
+				scores = append(scores, s.RelevanceScore)
+			}
+			if !reflect.DeepEqual(order, tt.wantOrder) {
+				t.Errorf("order = %v, want %v", order, tt.wantOrder)
+			}
+			for i := range scores {
+				if math.Abs(scores[i]-tt.wantScores[i]) > 1e-9 {
+					t.Errorf("scores = %v, want %v", scores, tt.wantScores)
+					break
+				}
+			}
+		})
+	}
+}
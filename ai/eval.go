@@ -0,0 +1,267 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+// EvalArticle is an article from an evaluation dataset with its human labels
+type EvalArticle struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Link        string   `json:"link"`
+	Source      string   `json:"source"`
+	Content     string   `json:"content"`
+	Rating      float64  `json:"rating"`   // Human relevance rating, 0-10
+	Category    string   `json:"category"` // Expected category, one of Categories
+	Tags        []string `json:"tags"`     // Expected tags; optional
+}
+
+// EvalReport holds the quality metrics of one evaluation run
+type EvalReport struct {
+	Articles         int
+	RankCorrelation  float64 // Spearman correlation between model scores and ratings; NaN if undefined
+	CategoryCorrect  int
+	CategoryAccuracy float64
+	TagOverlap       float64 // Mean Jaccard similarity of model and expected tags, over labeled articles
+	TaggedArticles   int     // Articles with expected tags
+	K                int
+	RelevantSelected int     // Selected articles among the human top K
+	PrecisionAtK     float64 // RelevantSelected / K
+	ScoreErrors      int     // Articles that could not be scored
+	TagErrors        int     // Articles that could not be tagged
+}
+
+// LoadEvalDataset reads labeled articles from a JSON Lines file
+func LoadEvalDataset(path string) ([]EvalArticle, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var dataset []EvalArticle
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var article EvalArticle
+		if err := json.Unmarshal(scanner.Bytes(), &article); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if article.Title == "" {
+			return nil, fmt.Errorf("%s:%d: missing title", path, line)
+		}
+		if article.Link == "" {
+			article.Link = fmt.Sprintf("eval:%d", line)
+		}
+		dataset = append(dataset, article)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(dataset) == 0 {
+		return nil, fmt.Errorf("%s: dataset is empty", path)
+	}
+	return dataset, nil
+}
+
+// Evaluate scores and tags every dataset article and compares the results with the
+// human labels. Precision@K compares the diversity-constrained selection of K articles
+// with the articles rated at least as high as the K-th best human rating.
+func (a *Analyzer) Evaluate(ctx context.Context, dataset []EvalArticle, k int) (*EvalReport, error) {
+	articles := make([]models.Article, len(dataset))
+	for i, item := range dataset {
+		articles[i] = models.Article{
+			Title:       item.Title,
+			Description: item.Description,
+			Link:        item.Link,
+			Source:      item.Source,
+			Content:     item.Content,
+		}
+	}
+
+	a.cacheStats = cacheStats{}
+	a.tagStats = tagStats{}
+	a.scoreErrors = 0
+	a.dedupedByEmbedding = false
+	a.usage = make(map[string]*models.StageUsage)
+
+	log.Printf("Scoring %d articles...", len(articles))
+	analyzed, scored := a.scoreArticles(ctx, articles)
+	if err := a.runErr(ctx); err != nil {
+		return nil, err
+	}
+	a.calibrateScores(analyzed, scored)
+
+	log.Printf("\nExtracting tags and categories for %d articles...", len(analyzed))
+	a.parallel(ctx, len(analyzed), func(i int) {
+		tags, category, err := a.extractTagsAndCategory(ctx, analyzed[i].Article)
+		if err != nil {
+			log.Printf("  ✗ Error extracting tags for '%s': %v", analyzed[i].Title, err)
+			a.mu.Lock()
+			a.tagStats.fallbacks++
+			a.mu.Unlock()
+			tags = []string{}
+			category = fallbackCategory
+		}
+		analyzed[i].Tags = tags
+		analyzed[i].Category = category
+	})
+	if err := a.runErr(ctx); err != nil {
+		return nil, err
+	}
+
+	report := &EvalReport{
+		Articles:    len(dataset),
+		K:           min(k, len(dataset)),
+		ScoreErrors: a.scoreErrors,
+		TagErrors:   a.tagStats.fallbacks,
+	}
+
+	scores := make([]float64, len(dataset))
+	ratings := make([]float64, len(dataset))
+	var tagOverlap float64
+	for i, item := range dataset {
+		scores[i] = analyzed[i].RelevanceScore
+		ratings[i] = item.Rating
+
+		if expected, ok := canonicalCategory(item.Category); ok && expected == analyzed[i].Category {
+			report.CategoryCorrect++
+		}
+		if len(item.Tags) > 0 {
+			report.TaggedArticles++
+			tagOverlap += jaccard(normalizeTags(item.Tags), analyzed[i].Tags)
+		}
+	}
+	report.RankCorrelation = spearman(scores, ratings)
+	report.CategoryAccuracy = float64(report.CategoryCorrect) / float64(report.Articles)
+	if report.TaggedArticles > 0 {
+		report.TagOverlap = tagOverlap / float64(report.TaggedArticles)
+	}
+
+	if report.K > 0 {
+		ranked := append([]models.AnalyzedArticle(nil), analyzed...)
+		sort.SliceStable(ranked, func(i, j int) bool {
+			return ranked[i].RelevanceScore > ranked[j].RelevanceScore
+		})
+		selected := a.selectWithDiversity(ranked, report.K, nopRecorder{})
+		report.RelevantSelected, report.PrecisionAtK = precisionAtK(dataset, selected, report.K)
+	}
+
+	return report, nil
+}
+
+// precisionAtK counts the selected articles rated at least as high as the K-th best
+// human rating, and returns the count and its share of k. k must be between 1 and the
+// dataset size.
+func precisionAtK(dataset []EvalArticle, selected []models.AnalyzedArticle, k int) (int, float64) {
+	sorted := make([]float64, len(dataset))
+	for i, item := range dataset {
+		sorted[i] = item.Rating
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(sorted)))
+	threshold := sorted[k-1]
+
+	relevant := make(map[string]bool)
+	for _, item := range dataset {
+		if item.Rating >= threshold {
+			relevant[item.Link] = true
+		}
+	}
+
+	hits := 0
+	for _, article := range selected {
+		if relevant[article.Link] {
+			hits++
+		}
+	}
+	return hits, float64(hits) / float64(k)
+}
+
+// spearman returns the Spearman rank correlation of x and y, or NaN when either is constant
+func spearman(x, y []float64) float64 {
+	rx, ry := ranks(x), ranks(y)
+
+	n := float64(len(x))
+	var meanX, meanY float64
+	for i := range rx {
+		meanX += rx[i] / n
+		meanY += ry[i] / n
+	}
+
+	var cov, varX, varY float64
+	for i := range rx {
+		dx, dy := rx[i]-meanX, ry[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return math.NaN()
+	}
+	return cov / math.Sqrt(varX*varY)
+}
+
+// ranks returns the 1-based rank of each value, averaging the ranks of ties
+func ranks(values []float64) []float64 {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return values[order[i]] < values[order[j]]
+	})
+
+	result := make([]float64, len(values))
+	for start := 0; start < len(order); {
+		end := start
+		for end+1 < len(order) && values[order[end+1]] == values[order[start]] {
+			end++
+		}
+		rank := float64(start+end)/2 + 1
+		for _, i := range order[start : end+1] {
+			result[i] = rank
+		}
+		start = end + 1
+	}
+	return result
+}
+
+// jaccard returns the Jaccard similarity of two tag sets
+func jaccard(a, b []string) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+
+	set := make(map[string]bool, len(a))
+	for _, tag := range a {
+		set[tag] = true
+	}
+	union := len(set)
+	common := 0
+	seen := make(map[string]bool, len(b))
+	for _, tag := range b {
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		if set[tag] {
+			common++
+		} else {
+			union++
+		}
+	}
+	return float64(common) / float64(union)
+}
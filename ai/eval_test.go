@@ -0,0 +1,128 @@
+package ai
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+func TestRanks(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   []float64
+	}{
+		{name: "distinct", values: []float64{30, 10, 20}, want: []float64{3, 1, 2}},
+		{name: "pair tied", values: []float64{5, 7, 7, 9}, want: []float64{1, 2.5, 2.5, 4}},
+		{name: "three tied", values: []float64{2, 1, 2, 2}, want: []float64{3, 1, 3, 3}},
+		{name: "all tied", values: []float64{4, 4, 4}, want: []float64{2, 2, 2}},
+		{name: "single", values: []float64{8}, want: []float64{1}},
+		{name: "empty", values: []float64{}, want: []float64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ranks(tt.values); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ranks(%v) = %v, want %v", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpearman(t *testing.T) {
+	tests := []struct {
+		name string
+		x, y []float64
+		want float64 // NaN when undefined
+	}{
+		{name: "same order", x: []float64{1, 2, 3, 4}, y: []float64{10, 20, 30, 40}, want: 1},
+		{name: "monotonic but not linear", x: []float64{1, 2, 3, 4}, y: []float64{1, 4, 9, 100}, want: 1},
+		{name: "reversed", x: []float64{1, 2, 3, 4, 5}, y: []float64{5, 4, 3, 2, 1}, want: -1},
+		{name: "adjacent swaps", x: []float64{1, 2, 3, 4, 5}, y: []float64{2, 1, 4, 3, 5}, want: 0.8},
+		// Ranks of y are 1, 2, 3.5, 5, 3.5: 8 / sqrt(10 * 9.5)
+		{name: "tied ratings", x: []float64{1, 2, 3, 4, 5}, y: []float64{5, 6, 7, 8, 7}, want: 8 / math.Sqrt(95)},
+		{name: "constant scores", x: []float64{6, 6, 6}, y: []float64{1, 2, 3}, want: math.NaN()},
+		{name: "single article", x: []float64{3}, y: []float64{7}, want: math.NaN()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := spearman(tt.x, tt.y)
+			if math.IsNaN(tt.want) {
+				if !math.IsNaN(got) {
+					t.Errorf("spearman = %v, want NaN", got)
+				}
+				return
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("spearman = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrecisionAtK(t *testing.T) {
+	dataset := []EvalArticle{
+		{Link: "a", Rating: 9},
+		{Link: "b", Rating: 8},
+		{Link: "c", Rating: 7},
+		{Link: "d", Rating: 7},
+		{Link: "e", Rating: 3},
+	}
+	picks := func(links ...string) []models.AnalyzedArticle {
+		selected := make([]models.AnalyzedArticle, len(links))
+		for i, link := range links {
+			selected[i].Link = link
+		}
+		return selected
+	}
+
+	tests := []struct {
+		name          string
+		selected      []models.AnalyzedArticle
+		k             int
+		wantRelevant  int
+		wantPrecision float64
+	}{
+		{name: "human top K selected", selected: picks("b", "a"), k: 2, wantRelevant: 2, wantPrecision: 1},
+		{name: "one of two relevant", selected: picks("a", "c"), k: 2, wantRelevant: 1, wantPrecision: 0.5},
+		{name: "none relevant", selected: picks("e"), k: 1, wantRelevant: 0, wantPrecision: 0},
+		{name: "ties with the K-th rating count", selected: picks("a", "b", "d"), k: 3, wantRelevant: 3, wantPrecision: 1},
+		{name: "short selection", selected: picks("a"), k: 3, wantRelevant: 1, wantPrecision: 1.0 / 3},
+		{name: "K covers the dataset", selected: picks("a", "b", "c", "d", "e"), k: 5, wantRelevant: 5, wantPrecision: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relevant, precision := precisionAtK(dataset, tt.selected, tt.k)
+			if relevant != tt.wantRelevant || math.Abs(precision-tt.wantPrecision) > 1e-9 {
+				t.Errorf("precisionAtK = %d, %v, want %d, %v", relevant, precision, tt.wantRelevant, tt.wantPrecision)
+			}
+		})
+	}
+}
+
+func TestJaccard(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		want float64
+	}{
+		{name: "identical", a: []string{"go", "sql"}, b: []string{"sql", "go"}, want: 1},
+		{name: "partial", a: []string{"go", "sql"}, b: []string{"go", "rust"}, want: 1.0 / 3},
+		{name: "disjoint", a: []string{"go"}, b: []string{"rust"}, want: 0},
+		{name: "duplicates ignored", a: []string{"go"}, b: []string{"go", "go"}, want: 1},
+		{name: "one empty", a: []string{"go"}, want: 0},
+		{name: "both empty", want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jaccard(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("jaccard(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package ai
+
+import (
+	"context"
+	"log"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+// ContentExtractor fetches the full text of an article's page
+type ContentExtractor interface {
+	Extract(ctx context.Context, article models.Article) (string, error)
+}
+
+// extractStats counts full-text extraction results for a single run
+type extractStats struct {
+	extracted int
+	fallbacks int // Articles that kept their feed content
+}
+
+// SetContentExtractor enables full-text extraction of selected articles before summarizing
+func (a *Analyzer) SetContentExtractor(extractor ContentExtractor) {
+	a.extractor = extractor
+}
+
+// extractContent replaces the feed content of selected articles with the full text of
+// their pages. Failed extractions keep the feed content. Pages are fetched once per ranking.
+func (a *Analyzer) extractContent(ctx context.Context, selected []models.AnalyzedArticle) {
+	if a.extractor == nil {
+		return
+	}
+
+	log.Printf("\nExtracting full text for %d articles...", len(selected))
+	a.parallel(ctx, len(selected), func(i int) {
+		a.mu.Lock()
+		content, ok := a.extracted[selected[i].Link]
+		a.mu.Unlock()
+
+		if !ok {
+			text, err := a.extractor.Extract(ctx, selected[i].Article)
+			a.mu.Lock()
+			if err != nil {
+				log.Printf("  ✗ Using feed content for '%s': %v", selected[i].Title, err)
+				a.extractStats.fallbacks++
+			} else {
+				log.Printf("  ✓ Extracted %d characters from '%s'", len([]rune(text)), selected[i].Title)
+				a.extractStats.extracted++
+			}
+			a.extracted[selected[i].Link] = text
+			a.mu.Unlock()
+			content = text
+		}
+
+		if content != "" {
+			selected[i].Content = content
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// fakeEmbeddingSize is the dimension of fake embedding vectors
+const fakeEmbeddingSize = 256
+
+// FakeProvider returns deterministic responses derived from the prompt text.
+// It needs no credentials or network access, so the pipeline can be exercised offline.
+type FakeProvider struct{}
+
+// NewFakeProvider creates a deterministic fake provider
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{}
+}
+
+// Name returns the provider identifier
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+// Model returns the fake model name
+func (p *FakeProvider) Model() string {
+	return "fake"
+}
+
+// Generate returns a stable, well-formed response for the request's task, with token
+// usage estimated from the text lengths
+func (p *FakeProvider) Generate(ctx context.Context, req Request) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	text, err := fakeResponse(req)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{
+		Text:  text,
+		Usage: Usage{PromptTokens: estimateTokens(req.Prompt), OutputTokens: estimateTokens(text)},
+	}, nil
+}
+
+// fakeResponse derives the response text for a request from its prompt
+func fakeResponse(req Request) (string, error) {
+	h := promptHash(req.Prompt)
+
+	switch req.Task {
+	case TaskScore:
+		return fmt.Sprintf("%.1f", float64(h%21)/2), nil
+	case TaskScoreBatch, TaskPersonalize:
+		return fakeBatchScores(req.Prompt), nil
+	case TaskTags:
+		data, err := json.Marshal(tagsResponse{
+			Category: Categories[h%uint32(len(Categories))],
+			Tags:     fakeTags(req.Prompt),
+		})
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	case TaskSummarize:
+		return fmt.Sprintf("A short summary of %q.", promptField(req.Prompt, "Title:")), nil
+	case TaskSummarizeBullets:
+		title := promptField(req.Prompt, "Title:")
+		data, err := json.Marshal([]string{
+			fmt.Sprintf("%s is covered.", title),
+			"Key details are explained.",
+			"Developers may want to follow up.",
+		})
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	case TaskIntro:
+		data, err := json.Marshal(map[string]string{
+			"intro":  fmt.Sprintf("Today's issue connects %d stories.", strings.Count(req.Prompt, "\n[")),
+			"teaser": fmt.Sprintf("Today in tech, issue %d", h%1000),
+		})
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	case TaskSummarizeWhy:
+		return fmt.Sprintf("%q matters because it affects how developers build software.", promptField(req.Prompt, "Title:")), nil
+	default:
+		return "", fmt.Errorf("fake provider does not support task %q", req.Task)
+	}
+}
+
+// Embed returns bag-of-words vectors so texts sharing words are similar
+func (p *FakeProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, fakeEmbeddingSize)
+		for _, word := range strings.Fields(strings.ToLower(text)) {
+			word = strings.Trim(word, ".,:;!?\"'()[]")
+			if len(word) > 2 {
+				vector[promptHash(word)%fakeEmbeddingSize]++
+			}
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+// Close is a no-op for the fake provider
+func (p *FakeProvider) Close() error {
+	return nil
+}
+
+// promptHash returns a stable hash of the prompt
+func promptHash(prompt string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(prompt))
+	return h.Sum32()
+}
+
+// promptField returns the value of the first "Label: value" line in the prompt
+func promptField(prompt, label string) string {
+	for _, line := range strings.Split(prompt, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, label) {
+			return strings.TrimSpace(strings.TrimPrefix(line, label))
+		}
+	}
+	return ""
+}
+
+// fakeTags picks up to three longer words from the article title as tags
+func fakeTags(prompt string) []string {
+	var tags []string
+	for _, word := range strings.Fields(strings.ToLower(promptField(prompt, "Title:"))) {
+		word = strings.Trim(word, ".,:;!?\"'()[]")
+		if len(word) > 3 {
+			tags = append(tags, word)
+		}
+		if len(tags) == 3 {
+			break
+		}
+	}
+	if len(tags) == 0 {
+		tags = []string{"tech"}
+	}
+	return tags
+}
+
+// fakeBatchScores scores every "[i] Title: ..." line of a batched prompt by hashing its title
+func fakeBatchScores(prompt string) string {
+	var entries []map[string]any
+	for _, line := range strings.Split(prompt, "\n") {
+		var index int
+		if _, err := fmt.Sscanf(line, "[%d] Title:", &index); err != nil {
+			continue
+		}
+		title := line[strings.Index(line, "Title:")+len("Title:"):]
+		entries = append(entries, map[string]any{
+			"index": index,
+			"score": float64(promptHash(strings.TrimSpace(title))%21) / 2,
+		})
+	}
+
+	data, _ := json.Marshal(entries)
+	return string(data)
+}
@@ -0,0 +1,138 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+const (
+	defaultGeminiModel          = "gemini-2.0-flash"
+	defaultGeminiEmbeddingModel = "text-embedding-004"
+)
+
+// GeminiProvider generates text with Google's Gemini API
+type GeminiProvider struct {
+	client         *genai.Client
+	httpClient     *http.Client // Transport of client; genai.Client has no Close of its own
+	model          string
+	embeddingModel string
+}
+
+// NewGeminiProvider creates a Gemini-backed provider
+func NewGeminiProvider(ctx context.Context, apiKey, model, embeddingModel string) (*GeminiProvider, error) {
+	httpClient := &http.Client{}
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     apiKey,
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: httpClient,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+	}
+
+	if model == "" {
+		model = defaultGeminiModel
+	}
+	if embeddingModel == "" {
+		embeddingModel = defaultGeminiEmbeddingModel
+	}
+
+	return &GeminiProvider{
+		client:         client,
+		httpClient:     httpClient,
+		model:          model,
+		embeddingModel: embeddingModel,
+	}, nil
+}
+
+// Name returns the provider identifier
+func (p *GeminiProvider) Name() string {
+	return "gemini"
+}
+
+// Model returns the Gemini model used for generation
+func (p *GeminiProvider) Model() string {
+	return p.model
+}
+
+// Generate sends the prompt to Gemini, requesting JSON output when a schema is set
+func (p *GeminiProvider) Generate(ctx context.Context, req Request) (*Response, error) {
+	var config *genai.GenerateContentConfig
+	if req.Schema != nil {
+		config = &genai.GenerateContentConfig{
+			ResponseMIMEType: "application/json",
+			ResponseSchema:   toGenaiSchema(req.Schema),
+		}
+	}
+
+	content := []*genai.Content{{Parts: []*genai.Part{genai.NewPartFromText(req.Prompt)}}}
+	response, err := p.client.Models.GenerateContent(ctx, p.model, content, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var usage Usage
+	if meta := response.UsageMetadata; meta != nil {
+		usage = Usage{
+			PromptTokens: int(meta.PromptTokenCount),
+			OutputTokens: int(meta.CandidatesTokenCount + meta.ThoughtsTokenCount),
+		}
+	}
+	return &Response{Text: response.Text(), Usage: usage}, nil
+}
+
+// Embed returns semantic-similarity embeddings for the texts
+func (p *GeminiProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	contents := make([]*genai.Content, len(texts))
+	for i, text := range texts {
+		contents[i] = genai.NewContentFromText(text, genai.RoleUser)
+	}
+
+	response, err := p.client.Models.EmbedContent(ctx, p.embeddingModel, contents, &genai.EmbedContentConfig{
+		TaskType: "SEMANTIC_SIMILARITY",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(response.Embeddings))
+	}
+
+	vectors := make([][]float32, len(response.Embeddings))
+	for i, embedding := range response.Embeddings {
+		vectors[i] = embedding.Values
+	}
+	return vectors, nil
+}
+
+// Close releases the provider's idle connections
+func (p *GeminiProvider) Close() error {
+	p.httpClient.CloseIdleConnections()
+	return nil
+}
+
+// toGenaiSchema converts a Schema into the genai representation
+func toGenaiSchema(s *Schema) *genai.Schema {
+	if s == nil {
+		return nil
+	}
+
+	schema := &genai.Schema{
+		Type:        genai.Type(strings.ToUpper(s.Type)),
+		Description: s.Description,
+		Required:    s.Required,
+		Enum:        s.Enum,
+		Items:       toGenaiSchema(s.Items),
+	}
+	if len(s.Properties) > 0 {
+		schema.Properties = make(map[string]*genai.Schema, len(s.Properties))
+		for name, prop := range s.Properties {
+			schema.Properties[name] = toGenaiSchema(prop)
+		}
+	}
+	return schema
+}
@@ -0,0 +1,94 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+const (
+	maxIntroLength  = 700 // Characters in the intro paragraph
+	maxTeaserLength = 90  // Characters in the subject teaser
+)
+
+// introSchema describes the JSON object expected from WriteIntro
+var introSchema = &Schema{
+	Type: "object",
+	Properties: map[string]*Schema{
+		"intro":  {Type: "string"},
+		"teaser": {Type: "string"},
+	},
+	Required: []string{"intro", "teaser"},
+}
+
+// WriteIntro writes the editorial intro paragraph and subject teaser for the selected
+// articles. In degrade budget mode it returns an empty editorial once the budget is spent.
+func (a *Analyzer) WriteIntro(ctx context.Context, selected []models.AnalyzedArticle) (models.Editorial, error) {
+	if len(selected) == 0 {
+		return models.Editorial{}, nil
+	}
+
+	degrade, err := a.checkBudget("writing the intro")
+	if err != nil {
+		return models.Editorial{}, err
+	}
+	if degrade {
+		log.Printf("  Skipping the editorial intro to save budget")
+		return models.Editorial{}, nil
+	}
+
+	list := make([]PromptArticle, len(selected))
+	for i, article := range selected {
+		list[i] = PromptArticle{
+			Index:    i + 1,
+			Title:    flattenText(article.Title, 0),
+			Category: article.Category,
+			Summary:  flattenText(article.Summary, 0),
+		}
+	}
+
+	prompt, err := a.prompts.render(promptIntro, PromptData{
+		Articles:        list,
+		MaxLength:       maxIntroLength,
+		MaxTeaserLength: maxTeaserLength,
+	})
+	if err != nil {
+		return models.Editorial{}, err
+	}
+
+	var responseText string
+	err = a.retry.Do(ctx, func() error {
+		text, err := a.generateStructured(ctx, TaskIntro, prompt, introSchema)
+		if err != nil {
+			return fmt.Errorf("failed to write intro: %w", err)
+		}
+
+		responseText = text
+		return nil
+	})
+	if err != nil {
+		return models.Editorial{}, err
+	}
+
+	var resp struct {
+		Intro  string `json:"intro"`
+		Teaser string `json:"teaser"`
+	}
+	if err := json.Unmarshal([]byte(extractJSON(responseText)), &resp); err != nil {
+		return models.Editorial{}, fmt.Errorf("invalid intro response: %w", err)
+	}
+
+	// Over-long text is shortened rather than re-requested; the intro is optional
+	editorial := models.Editorial{
+		Intro:  flattenText(resp.Intro, maxIntroLength-1),
+		Teaser: flattenText(resp.Teaser, maxTeaserLength-1),
+	}
+	if editorial.Intro == "" || editorial.Teaser == "" {
+		return models.Editorial{}, fmt.Errorf("intro response is missing the intro or teaser")
+	}
+	log.Printf("  ✓ Wrote intro and teaser: %s", editorial.Teaser)
+	return editorial, nil
+}
@@ -0,0 +1,108 @@
+package ai
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token-bucket limiter shared by all analyzer workers. It enforces a
+// requests-per-minute limit and, optionally, an estimated tokens-per-minute limit.
+type RateLimiter struct {
+	mu       sync.Mutex
+	requests bucket
+	tokens   bucket // Zero rate disables token limiting
+}
+
+// bucket holds up to capacity units and refills at rate units per second
+type bucket struct {
+	capacity float64
+	rate     float64
+	level    float64
+	updated  time.Time
+}
+
+// NewRateLimiter creates a limiter for the given per-minute limits. Requests may burst
+// up to one second's worth; tokens may burst up to a full minute's budget since prompt
+// sizes vary widely. A limit of zero or less disables that limit.
+func NewRateLimiter(requestsPerMinute, tokensPerMinute int) *RateLimiter {
+	now := time.Now()
+	l := &RateLimiter{}
+	if requestsPerMinute > 0 {
+		rate := float64(requestsPerMinute) / 60
+		l.requests = bucket{capacity: max(1, rate), rate: rate, updated: now}
+		l.requests.level = l.requests.capacity
+	}
+	if tokensPerMinute > 0 {
+		l.tokens = bucket{capacity: float64(tokensPerMinute), rate: float64(tokensPerMinute) / 60, updated: now}
+		l.tokens.level = l.tokens.capacity
+	}
+	return l
+}
+
+// Wait blocks until one request using the estimated number of tokens may proceed,
+// or returns the context's error if it is cancelled first
+func (l *RateLimiter) Wait(ctx context.Context, tokens int) error {
+	for {
+		delay := l.reserve(float64(tokens))
+		if delay == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes capacity from both buckets if available and returns zero, otherwise
+// it takes nothing and returns how long to wait before trying again
+func (l *RateLimiter) reserve(tokens float64) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.requests.refill(now)
+	l.tokens.refill(now)
+
+	// A single request larger than the bucket could never proceed, so cap its cost
+	tokens = min(tokens, l.tokens.capacity)
+
+	delay := max(l.requests.wait(1), l.tokens.wait(tokens))
+	if delay > 0 {
+		return delay
+	}
+
+	l.requests.take(1)
+	l.tokens.take(tokens)
+	return 0
+}
+
+// refill adds the units accrued since the last update
+func (b *bucket) refill(now time.Time) {
+	if b.rate == 0 {
+		return
+	}
+	b.level = min(b.capacity, b.level+now.Sub(b.updated).Seconds()*b.rate)
+	b.updated = now
+}
+
+// wait returns how long until n units are available
+func (b *bucket) wait(n float64) time.Duration {
+	if b.rate == 0 || b.level >= n {
+		return 0
+	}
+	return time.Duration((n - b.level) / b.rate * float64(time.Second))
+}
+
+// take removes n units from the bucket
+func (b *bucket) take(n float64) {
+	if b.rate == 0 {
+		return
+	}
+	b.level -= n
+}
@@ -0,0 +1,169 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+// rewind moves a bucket's last refill back by d, as if d had passed
+func (b *bucket) rewind(d time.Duration) {
+	b.updated = b.updated.Add(-d)
+}
+
+func TestRateLimiterRequestBurstAndRefill(t *testing.T) {
+	// 120 requests a minute refill 2 per second, with a burst of one second's worth
+	limiter := NewRateLimiter(120, 0)
+
+	for i := 0; i < 2; i++ {
+		if delay := limiter.reserve(0); delay != 0 {
+			t.Fatalf("request %d of the burst waited %v", i+1, delay)
+		}
+	}
+	delay := limiter.reserve(0)
+	if delay <= 0 || delay > 500*time.Millisecond {
+		t.Fatalf("request after the burst waits %v, want up to 500ms", delay)
+	}
+
+	limiter.requests.rewind(500 * time.Millisecond)
+	if delay := limiter.reserve(0); delay != 0 {
+		t.Errorf("request after half a second waited %v", delay)
+	}
+
+	// Refilling never exceeds the burst
+	limiter.requests.rewind(time.Hour)
+	for i := 0; i < 2; i++ {
+		if delay := limiter.reserve(0); delay != 0 {
+			t.Fatalf("request %d after an idle hour waited %v", i+1, delay)
+		}
+	}
+	if delay := limiter.reserve(0); delay == 0 {
+		t.Error("idle hour allowed more than the burst")
+	}
+}
+
+func TestRateLimiterTokenBucket(t *testing.T) {
+	// 600 tokens a minute refill 10 per second, with a burst of the full minute
+	limiter := NewRateLimiter(0, 600)
+
+	if delay := limiter.reserve(400); delay != 0 {
+		t.Fatalf("first request waited %v", delay)
+	}
+	delay := limiter.reserve(300)
+	if want := 10 * time.Second; delay < want-50*time.Millisecond || delay > want {
+		t.Fatalf("request over the remaining 200 tokens waits %v, want about %v", delay, want)
+	}
+
+	limiter.tokens.rewind(10 * time.Second)
+	if delay := limiter.reserve(300); delay != 0 {
+		t.Errorf("request after refilling waited %v", delay)
+	}
+
+	// A request larger than the bucket waits for a full bucket instead of forever
+	limiter.tokens.rewind(time.Hour)
+	if delay := limiter.reserve(5000); delay != 0 {
+		t.Errorf("oversized request with a full bucket waited %v", delay)
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	limiter := NewRateLimiter(0, 0)
+	for i := 0; i < 1000; i++ {
+		if err := limiter.Wait(context.Background(), 100000); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	limiter := NewRateLimiter(1, 0) // One request, then one a minute
+	if err := limiter.Wait(context.Background(), 0); err != nil {
+		t.Fatalf("first Wait: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := limiter.Wait(ctx, 0)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Wait returned %v after cancellation", elapsed)
+	}
+
+	// The cancelled wait took nothing, so the next request is still a minute away
+	if delay := limiter.reserve(0); delay < 50*time.Second {
+		t.Errorf("next request waits %v, want most of a minute", delay)
+	}
+}
+
+func TestParallel(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency int
+		n           int
+	}{
+		{name: "single worker", concurrency: 1, n: 10},
+		{name: "more jobs than workers", concurrency: 3, n: 20},
+		{name: "more workers than jobs", concurrency: 8, n: 2},
+		{name: "no jobs", concurrency: 4, n: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewAnalyzer(NewFakeProvider(), &models.Config{AIConcurrency: tt.concurrency})
+
+			var inFlight, peak atomic.Int32
+			results := make([]int, tt.n)
+			analyzer.parallel(context.Background(), tt.n, func(i int) {
+				current := inFlight.Add(1)
+				for {
+					seen := peak.Load()
+					if current <= seen || peak.CompareAndSwap(seen, current) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				results[i] = i * i
+				inFlight.Add(-1)
+			})
+
+			for i, got := range results {
+				if got != i*i {
+					t.Errorf("result %d = %d, want %d", i, got, i*i)
+				}
+			}
+			if limit := int32(min(tt.concurrency, tt.n)); peak.Load() > limit {
+				t.Errorf("%d jobs ran at once, want at most %d", peak.Load(), limit)
+			}
+		})
+	}
+}
+
+func TestParallelStopsOnCancel(t *testing.T) {
+	analyzer := NewAnalyzer(NewFakeProvider(), &models.Config{AIConcurrency: 2})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var mu sync.Mutex
+	started := 0
+	analyzer.parallel(ctx, 100, func(i int) {
+		mu.Lock()
+		started++
+		if started == 4 {
+			cancel()
+		}
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+	})
+
+	// Jobs already handed to a worker still run, but no new ones start
+	if started > 4+2 {
+		t.Errorf("%d jobs started after cancelling at the 4th, want at most 6", started)
+	}
+}
@@ -0,0 +1,253 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultOpenAIBaseURL        = "https://api.openai.com/v1"
+	defaultOpenAIModel          = "gpt-4o-mini"
+	defaultOpenAIEmbeddingModel = "text-embedding-3-small"
+)
+
+// OpenAIProvider generates text with any OpenAI-compatible chat completions API
+// (OpenAI, vLLM, Ollama, llama.cpp server, LM Studio, ...)
+type OpenAIProvider struct {
+	baseURL        string
+	apiKey         string
+	model          string
+	embeddingModel string
+	httpClient     *http.Client
+}
+
+// NewOpenAIProvider creates a provider for an OpenAI-compatible endpoint
+func NewOpenAIProvider(baseURL, apiKey, model, embeddingModel string) *OpenAIProvider {
+	if baseURL == "" {
+		baseURL = defaultOpenAIBaseURL
+	}
+	if model == "" {
+		model = defaultOpenAIModel
+	}
+	if embeddingModel == "" {
+		embeddingModel = defaultOpenAIEmbeddingModel
+	}
+
+	return &OpenAIProvider{
+		baseURL:        strings.TrimRight(baseURL, "/"),
+		apiKey:         apiKey,
+		model:          model,
+		embeddingModel: embeddingModel,
+		httpClient:     &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// HTTPError is a non-2xx response from an HTTP-based provider
+type HTTPError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // Parsed Retry-After header, zero if absent
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("API error: status %d, body: %s", e.StatusCode, e.Body)
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIJSONSchema struct {
+	Name   string         `json:"name"`
+	Schema map[string]any `json:"schema"`
+}
+
+type openAIResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *openAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+type openAIChatRequest struct {
+	Model          string                `json:"model"`
+	Messages       []openAIMessage       `json:"messages"`
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+}
+
+type openAIChatResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+type openAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Name returns the provider identifier
+func (p *OpenAIProvider) Name() string {
+	return "openai"
+}
+
+// Model returns the model used for generation
+func (p *OpenAIProvider) Model() string {
+	return p.model
+}
+
+// Generate sends the prompt as a single user message to the chat completions endpoint
+func (p *OpenAIProvider) Generate(ctx context.Context, req Request) (*Response, error) {
+	chatReq := openAIChatRequest{
+		Model:    p.model,
+		Messages: []openAIMessage{{Role: "user", Content: req.Prompt}},
+	}
+	if req.Schema != nil {
+		chatReq.ResponseFormat = &openAIResponseFormat{
+			Type: "json_schema",
+			JSONSchema: &openAIJSONSchema{
+				Name:   string(req.Task),
+				Schema: toJSONSchema(req.Schema),
+			},
+		}
+	}
+
+	var chatResp openAIChatResponse
+	if err := p.post(ctx, "/chat/completions", chatReq, &chatResp); err != nil {
+		return nil, err
+	}
+	if len(chatResp.Choices) == 0 {
+		return nil, fmt.Errorf("openai API returned no choices")
+	}
+
+	return &Response{
+		Text: chatResp.Choices[0].Message.Content,
+		Usage: Usage{
+			PromptTokens: chatResp.Usage.PromptTokens,
+			OutputTokens: chatResp.Usage.CompletionTokens,
+		},
+	}, nil
+}
+
+// Embed returns embeddings for the texts from the /embeddings endpoint
+func (p *OpenAIProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var embedResp openAIEmbeddingResponse
+	if err := p.post(ctx, "/embeddings", openAIEmbeddingRequest{Model: p.embeddingModel, Input: texts}, &embedResp); err != nil {
+		return nil, err
+	}
+	if len(embedResp.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(embedResp.Data))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range embedResp.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, nil
+}
+
+// post sends a JSON request to the API path and decodes the JSON response into out
+func (p *OpenAIProvider) post(ctx context.Context, path string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	httpResp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if httpResp.StatusCode >= 400 {
+		return &HTTPError{
+			StatusCode: httpResp.StatusCode,
+			Body:       string(respBody),
+			RetryAfter: parseRetryAfter(httpResp.Header.Get("Retry-After")),
+		}
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// Close cleans up the provider resources
+func (p *OpenAIProvider) Close() error {
+	p.httpClient.CloseIdleConnections()
+	return nil
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(0, time.Until(date))
+	}
+	return 0
+}
+
+// toJSONSchema converts a Schema into a JSON Schema document
+func toJSONSchema(s *Schema) map[string]any {
+	schema := map[string]any{"type": s.Type}
+	if s.Description != "" {
+		schema["description"] = s.Description
+	}
+	if len(s.Enum) > 0 {
+		schema["enum"] = s.Enum
+	}
+	if s.Items != nil {
+		schema["items"] = toJSONSchema(s.Items)
+	}
+	if len(s.Properties) > 0 {
+		props := make(map[string]any, len(s.Properties))
+		for name, prop := range s.Properties {
+			props[name] = toJSONSchema(prop)
+		}
+		schema["properties"] = props
+	}
+	if len(s.Required) > 0 {
+		schema["required"] = s.Required
+	}
+	return schema
+}
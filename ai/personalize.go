@@ -0,0 +1,131 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+const (
+	preferredCategoryBoost = 1.5 // Added to the score of articles in a preferred category
+	interestWeight         = 0.5 // Share of the personalized score taken from interest match
+)
+
+// Personalize re-ranks the shared candidate pool for a single user and selects and
+// summarizes their top N. Articles are scored once globally by RankArticles; only this
+// ranking step runs per user. Blocked categories are dropped, preferred categories are
+// boosted, and free-text interests are matched against the pool with one LLM call.
+func (a *Analyzer) Personalize(ctx context.Context, ranked []models.AnalyzedArticle, profile models.UserProfile, topN int) ([]models.AnalyzedArticle, error) {
+	if profile.IsEmpty() {
+		return a.selectFromRanked(ctx, ranked, topN, nopRecorder{})
+	}
+
+	degrade, err := a.checkBudget("personalizing")
+	if err != nil {
+		return nil, err
+	}
+	if degrade {
+		log.Printf("  Skipping personalization to save budget, using the shared ranking")
+		return a.selectFromRanked(ctx, ranked, topN, nopRecorder{})
+	}
+
+	blocked := make(map[string]bool)
+	for _, category := range profile.BlockedCategories {
+		blocked[strings.ToLower(category)] = true
+	}
+	preferred := make(map[string]bool)
+	for _, category := range profile.PreferredCategories {
+		preferred[strings.ToLower(category)] = true
+	}
+
+	// Only tagged candidates are eligible, since categories drive the re-ranking
+	poolSize := min(topN*candidateMultiplier, len(ranked))
+	pool := make([]models.AnalyzedArticle, 0, poolSize)
+	for _, article := range ranked[:poolSize] {
+		if blocked[strings.ToLower(article.Category)] {
+			continue
+		}
+		pool = append(pool, article)
+	}
+
+	var interestScores map[int]float64
+	if profile.Interests != "" && len(pool) > 0 {
+		interestScores, err = a.scoreInterests(ctx, pool, profile.Interests)
+		if err != nil {
+			log.Printf("  ✗ Error matching interests, ranking by global score only: %v", err)
+		}
+	}
+
+	personalScores := make(map[string]float64, len(pool))
+	for i, article := range pool {
+		score := article.RelevanceScore
+		if interest, ok := interestScores[i]; ok {
+			score = (1-interestWeight)*score + interestWeight*interest
+		}
+		if preferred[strings.ToLower(article.Category)] {
+			score += preferredCategoryBoost
+		}
+		personalScores[article.Link] = score
+	}
+
+	sort.SliceStable(pool, func(i, j int) bool {
+		return personalScores[pool[i].Link] > personalScores[pool[j].Link]
+	})
+
+	log.Printf("Personalized ranking (%d candidates, %d blocked):", len(pool), poolSize-len(pool))
+	for i, article := range pool {
+		if i >= topN {
+			break
+		}
+		log.Printf("  %d. [%.1f → %.1f] %s (Category: %s)", i+1, article.RelevanceScore, personalScores[article.Link], article.Title, article.Category)
+	}
+
+	return a.selectFromRanked(ctx, pool, topN, nopRecorder{})
+}
+
+// scoreInterests rates how well each pool article matches the user's interests (0-10),
+// keyed by position in the pool
+func (a *Analyzer) scoreInterests(ctx context.Context, pool []models.AnalyzedArticle, interests string) (map[int]float64, error) {
+	list := make([]PromptArticle, len(pool))
+	for i, article := range pool {
+		list[i] = PromptArticle{Index: i, Title: flattenText(article.Title, 0), Tags: article.Tags}
+	}
+
+	prompt, err := a.prompts.render(promptPersonalize, PromptData{Interests: flattenText(interests, 0), Articles: list})
+	if err != nil {
+		return nil, err
+	}
+
+	var responseText string
+	err = a.retry.Do(ctx, func() error {
+		text, err := a.generateStructured(ctx, TaskPersonalize, prompt, batchScoreSchema)
+		if err != nil {
+			return fmt.Errorf("failed to match interests: %w", err)
+		}
+
+		responseText = text
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []batchScore
+	if err := json.Unmarshal([]byte(extractJSON(responseText)), &entries); err != nil {
+		return nil, fmt.Errorf("invalid interests response: %w", err)
+	}
+
+	scores := make(map[int]float64, len(entries))
+	for _, entry := range entries {
+		if entry.Index == nil || entry.Score == nil || *entry.Index < 0 || *entry.Index >= len(pool) || *entry.Score < 0 || *entry.Score > 10 {
+			continue
+		}
+		scores[*entry.Index] = *entry.Score
+	}
+	return scores, nil
+}
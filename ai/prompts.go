@@ -0,0 +1,219 @@
+package ai
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Prompt template names. Each is stored as <name>.<version>.tmpl, e.g. score.v1.tmpl.
+const (
+	promptScore               = "score"
+	promptScoreBatch          = "score_batch"
+	promptTags                = "tags"
+	promptTagsCorrection      = "tags_correction"
+	promptSummarize           = "summarize"
+	promptSummarizeBullets    = "summarize_bullets"
+	promptSummarizeWhy        = "summarize_why"
+	promptSummarizeCorrection = "summarize_correction"
+	promptPersonalize         = "personalize"
+	promptIntro               = "intro"
+)
+
+var promptNames = []string{
+	promptScore, promptScoreBatch, promptTags, promptTagsCorrection, promptSummarize,
+	promptSummarizeBullets, promptSummarizeWhy, promptSummarizeCorrection, promptPersonalize,
+	promptIntro,
+}
+
+// cachedPrompts lists the templates whose versions make up a cached task's key, so
+// editing any of them invalidates that task's cached results
+var cachedPrompts = map[Task][]string{
+	TaskScore:            {promptScore, promptScoreBatch},
+	TaskTags:             {promptTags, promptTagsCorrection},
+	TaskSummarize:        {promptSummarize, promptSummarizeCorrection},
+	TaskSummarizeBullets: {promptSummarizeBullets, promptSummarizeCorrection},
+	TaskSummarizeWhy:     {promptSummarizeWhy, promptSummarizeCorrection},
+}
+
+//go:embed prompts/*.tmpl
+var defaultPromptFS embed.FS
+
+// PromptData is the data available to prompt templates. Single-article prompts use
+// Title, Description and Content; batched prompts range over Articles.
+type PromptData struct {
+	Title           string
+	Description     string
+	Content         string
+	Articles        []PromptArticle
+	Categories      []string // Allowed categories
+	Interests       string   // The subscriber's interests (personalize)
+	Prompt          string   // The original prompt (corrections)
+	Previous        string   // The rejected response (corrections)
+	Error           string   // Why the response was rejected (corrections)
+	MaxTagLength    int
+	MaxLength       int // Character limit of a summary or intro, or of each bullet
+	MaxTeaserLength int // Character limit of the subject teaser (intro)
+}
+
+// PromptArticle is one article of a batched prompt, flattened onto a single line
+type PromptArticle struct {
+	Index       int
+	Title       string
+	Description string
+	Tags        []string
+	Category    string
+	Summary     string
+}
+
+// Prompts holds the parsed template for every prompt the analyzer sends
+type Prompts struct {
+	templates map[string]*template.Template
+	versions  map[string]string
+}
+
+// DefaultPrompts returns the built-in prompt templates
+func DefaultPrompts() *Prompts {
+	prompts, err := loadPrompts(defaultPromptFS, "prompts", nil)
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in prompts: %v", err))
+	}
+	return prompts
+}
+
+// LoadPrompts reads prompt templates from dir, using the built-in template for any
+// prompt the directory does not define. An empty dir returns the built-in prompts.
+func LoadPrompts(dir string) (*Prompts, error) {
+	defaults := DefaultPrompts()
+	if dir == "" {
+		return defaults, nil
+	}
+
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("prompts directory: %w", err)
+	}
+	prompts, err := loadPrompts(os.DirFS(dir), ".", defaults)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompts from %s: %w", dir, err)
+	}
+	return prompts, nil
+}
+
+// loadPrompts parses the <name>.<version>.tmpl files in dir. Prompts missing from dir
+// are taken from fallback, or reported as an error when fallback is nil.
+func loadPrompts(fsys fs.FS, dir string, fallback *Prompts) (*Prompts, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+
+	prompts := &Prompts{
+		templates: make(map[string]*template.Template),
+		versions:  make(map[string]string),
+	}
+	for _, file := range files {
+		name, version, ok := strings.Cut(strings.TrimSuffix(path.Base(file), ".tmpl"), ".")
+		if !ok || version == "" {
+			return nil, fmt.Errorf("%s: prompt files must be named <name>.<version>.tmpl", path.Base(file))
+		}
+		if !isPromptName(name) {
+			log.Printf("Warning: Ignoring unknown prompt template %s", path.Base(file))
+			continue
+		}
+		if existing, ok := prompts.versions[name]; ok {
+			return nil, fmt.Errorf("prompt %q has more than one version (%s and %s)", name, existing, version)
+		}
+
+		text, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		tmpl, err := template.New(name).Funcs(template.FuncMap{"join": strings.Join}).Parse(string(text))
+		if err != nil {
+			return nil, err
+		}
+		// Catch references to fields PromptData doesn't have before the first run uses them
+		if err := tmpl.Execute(io.Discard, samplePromptData); err != nil {
+			return nil, err
+		}
+
+		prompts.templates[name] = tmpl
+		prompts.versions[name] = version
+		if fallback != nil {
+			log.Printf("Using prompt %s.%s from %s", name, version, path.Base(file))
+		}
+	}
+
+	for _, name := range promptNames {
+		if _, ok := prompts.templates[name]; ok {
+			continue
+		}
+		if fallback == nil {
+			return nil, fmt.Errorf("missing prompt template %q", name)
+		}
+		prompts.templates[name] = fallback.templates[name]
+		prompts.versions[name] = fallback.versions[name]
+	}
+	return prompts, nil
+}
+
+// Version identifies every prompt in use, e.g. "personalize.v1,score.v2,..."
+func (p *Prompts) Version() string {
+	names := make([]string, 0, len(p.versions))
+	for name, version := range p.versions {
+		names = append(names, name+"."+version)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// cacheVersion identifies the prompts a cached task's results were produced with
+func (p *Prompts) cacheVersion(task Task) string {
+	parts := make([]string, 0, len(cachedPrompts[task]))
+	for _, name := range cachedPrompts[task] {
+		parts = append(parts, name+"."+p.versions[name])
+	}
+	return strings.Join(parts, "+")
+}
+
+// render executes the named template with data
+func (p *Prompts) render(name string, data PromptData) (string, error) {
+	var b strings.Builder
+	if err := p.templates[name].Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render %s prompt: %w", name, err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// isPromptName reports whether name is a prompt the analyzer uses
+func isPromptName(name string) bool {
+	for _, known := range promptNames {
+		if name == known {
+			return true
+		}
+	}
+	return false
+}
+
+// samplePromptData exercises every field when validating a template at load time
+var samplePromptData = PromptData{
+	Title:           "Title",
+	Description:     "Description",
+	Content:         "Content",
+	Articles:        []PromptArticle{{Index: 0, Title: "Title", Description: "Description", Tags: []string{"tag"}, Category: "Category", Summary: "Summary"}},
+	Categories:      Categories,
+	Interests:       "Interests",
+	Prompt:          "Prompt",
+	Previous:        "Previous",
+	Error:           "Error",
+	MaxTagLength:    maxTagLength,
+	MaxLength:       100,
+	MaxTeaserLength: 50,
+}
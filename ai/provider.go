@@ -0,0 +1,82 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+// Task identifies which analyzer stage a request belongs to
+type Task string
+
+const (
+	TaskScore            Task = "score"
+	TaskScoreBatch       Task = "score_batch"
+	TaskTags             Task = "tags"
+	TaskSummarize        Task = "summarize"
+	TaskSummarizeBullets Task = "summarize_bullets"
+	TaskSummarizeWhy     Task = "summarize_why"
+	TaskPersonalize      Task = "personalize"
+	TaskIntro            Task = "intro"
+)
+
+// Schema describes the JSON structure a provider should return for structured output
+type Schema struct {
+	Type        string             // "object", "array", "string", "number", "integer" or "boolean"
+	Description string             // Optional description for the model
+	Properties  map[string]*Schema // Fields of an object
+	Required    []string           // Required object fields
+	Items       *Schema            // Element schema of an array
+	Enum        []string           // Allowed values of a string
+}
+
+// Request is a single prompt sent to a provider
+type Request struct {
+	Task   Task
+	Prompt string
+	Schema *Schema // If set, the provider must respond with JSON matching this schema
+}
+
+// Response is the text generated by a provider
+type Response struct {
+	Text  string
+	Usage Usage // Zero when the provider does not report usage
+}
+
+// Usage is the token count billed for a single request
+type Usage struct {
+	PromptTokens int
+	OutputTokens int // Generated tokens, including any thinking tokens
+}
+
+// Provider generates text from prompts using an LLM backend
+type Provider interface {
+	// Name returns the provider identifier (e.g. "gemini")
+	Name() string
+	// Model returns the model used for generation
+	Model() string
+	// Generate sends a request to the model and returns its response
+	Generate(ctx context.Context, req Request) (*Response, error)
+	// Embed returns one embedding vector per input text, in order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// Close releases any resources held by the provider
+	Close() error
+}
+
+// NewProvider creates the provider selected in the configuration
+func NewProvider(ctx context.Context, cfg *models.Config) (Provider, error) {
+	switch cfg.AIProvider {
+	case "", "gemini":
+		if cfg.GeminiAPIKey == "" {
+			return nil, fmt.Errorf("GEMINI_API_KEY environment variable is required")
+		}
+		return NewGeminiProvider(ctx, cfg.GeminiAPIKey, cfg.AIModel, cfg.AIEmbeddingModel)
+	case "openai":
+		return NewOpenAIProvider(cfg.OpenAIBaseURL, cfg.OpenAIAPIKey, cfg.AIModel, cfg.AIEmbeddingModel), nil
+	case "fake":
+		return NewFakeProvider(), nil
+	default:
+		return nil, fmt.Errorf("unknown AI provider: %s", cfg.AIProvider)
+	}
+}
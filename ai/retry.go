@@ -0,0 +1,132 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+const (
+	retryBaseDelay = 1 * time.Second
+	retryMaxDelay  = 30 * time.Second
+)
+
+// RetryPolicy retries transient provider failures with jittered exponential backoff
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts including the first; values below 1 mean a single attempt
+	MaxElapsed  time.Duration // Give up once this much time has passed; 0 means no limit
+}
+
+// Do calls fn until it succeeds, returns a non-retryable error, or the policy's limits
+// are reached. Waiting between attempts stops immediately when ctx is cancelled.
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	start := time.Now()
+	maxAttempts := max(1, p.MaxAttempts)
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		retryable, hint := classifyError(err)
+		if !retryable {
+			return err
+		}
+		if attempt >= maxAttempts {
+			return fmt.Errorf("max retries exceeded after %d attempts: %w", attempt, err)
+		}
+
+		delay := backoffDelay(attempt)
+		if hint > delay {
+			delay = hint
+		}
+		if p.MaxElapsed > 0 && time.Since(start)+delay > p.MaxElapsed {
+			return fmt.Errorf("retry time limit of %v exceeded after %d attempts: %w", p.MaxElapsed, attempt, err)
+		}
+
+		log.Printf("  Retry attempt %d/%d after %v: %v", attempt, maxAttempts-1, delay.Round(time.Millisecond), err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoffDelay returns the jittered delay before the given retry: an exponential
+// base (1s, 2s, 4s, ... capped at retryMaxDelay) scaled randomly into [50%, 100%]
+func backoffDelay(attempt int) time.Duration {
+	delay := retryMaxDelay
+	if attempt <= 5 {
+		delay = min(retryMaxDelay, retryBaseDelay<<(attempt-1))
+	}
+	return delay/2 + time.Duration(rand.Int64N(int64(delay/2)+1))
+}
+
+// classifyError reports whether err is worth retrying and any server-provided delay hint
+func classifyError(err error) (bool, time.Duration) {
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		return retryableStatus(apiErr.Code), genaiRetryDelay(apiErr)
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return retryableStatus(httpErr.StatusCode), httpErr.RetryAfter
+	}
+
+	// Deadline errors that aren't from the caller's context are client timeouts
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true, 0
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true, 0
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true, 0
+	}
+
+	return false, 0
+}
+
+// retryableStatus reports whether an HTTP status indicates a transient failure
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// genaiRetryDelay extracts the retry delay from a google.rpc.RetryInfo error detail
+func genaiRetryDelay(apiErr genai.APIError) time.Duration {
+	for _, detail := range apiErr.Details {
+		if detail["@type"] != "type.googleapis.com/google.rpc.RetryInfo" {
+			continue
+		}
+		if delayStr, ok := detail["retryDelay"].(string); ok {
+			if delay, err := time.ParseDuration(delayStr); err == nil {
+				return delay
+			}
+		}
+	}
+	return 0
+}
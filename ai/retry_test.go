@@ -0,0 +1,231 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// retryInfo builds a genai error detail carrying a retry delay
+func retryInfo(delay string) map[string]any {
+	return map[string]any{
+		"@type":      "type.googleapis.com/google.rpc.RetryInfo",
+		"retryDelay": delay,
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantRetryable bool
+		wantHint      time.Duration
+	}{
+		{
+			name:          "genai rate limit with retry delay",
+			err:           genai.APIError{Code: http.StatusTooManyRequests, Details: []map[string]any{{"@type": "type.googleapis.com/google.rpc.Help"}, retryInfo("17s")}},
+			wantRetryable: true,
+			wantHint:      17 * time.Second,
+		},
+		{
+			name:          "genai server error without details",
+			err:           genai.APIError{Code: http.StatusInternalServerError},
+			wantRetryable: true,
+		},
+		{
+			name:          "genai unparseable retry delay ignored",
+			err:           genai.APIError{Code: http.StatusServiceUnavailable, Details: []map[string]any{retryInfo("soon")}},
+			wantRetryable: true,
+		},
+		{
+			name: "genai bad request",
+			err:  genai.APIError{Code: http.StatusBadRequest, Details: []map[string]any{retryInfo("5s")}},
+			// The hint is still reported, but the error is not retried
+			wantHint: 5 * time.Second,
+		},
+		{
+			name:          "wrapped HTTP rate limit with Retry-After",
+			err:           fmt.Errorf("scoring failed: %w", &HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: 3 * time.Second}),
+			wantRetryable: true,
+			wantHint:      3 * time.Second,
+		},
+		{
+			name:          "HTTP gateway timeout",
+			err:           &HTTPError{StatusCode: http.StatusGatewayTimeout},
+			wantRetryable: true,
+		},
+		{
+			name: "HTTP unauthorized",
+			err:  &HTTPError{StatusCode: http.StatusUnauthorized},
+		},
+		{
+			name:          "client timeout",
+			err:           fmt.Errorf("request failed: %w", context.DeadlineExceeded),
+			wantRetryable: true,
+		},
+		{
+			name:          "connection reset",
+			err:           fmt.Errorf("read: %w", syscall.ECONNRESET),
+			wantRetryable: true,
+		},
+		{
+			name:          "truncated response",
+			err:           io.ErrUnexpectedEOF,
+			wantRetryable: true,
+		},
+		{
+			name: "plain error",
+			err:  errors.New("invalid response"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retryable, hint := classifyError(tt.err)
+			if retryable != tt.wantRetryable || hint != tt.wantHint {
+				t.Errorf("classifyError = %v, %v, want %v, %v", retryable, hint, tt.wantRetryable, tt.wantHint)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	retryable := &HTTPError{StatusCode: http.StatusServiceUnavailable}
+	permanent := &HTTPError{StatusCode: http.StatusBadRequest}
+
+	tests := []struct {
+		name      string
+		policy    RetryPolicy
+		errs      []error // Returned by successive attempts; nil once exhausted
+		wantCalls int
+		wantErr   string // Substring of the expected error; empty for success
+	}{
+		{
+			name:      "success on the first attempt",
+			policy:    RetryPolicy{MaxAttempts: 3},
+			wantCalls: 1,
+		},
+		{
+			name:      "non-retryable error returned at once",
+			policy:    RetryPolicy{MaxAttempts: 3},
+			errs:      []error{permanent},
+			wantCalls: 1,
+			wantErr:   "status 400",
+		},
+		{
+			name:      "plain error returned at once",
+			policy:    RetryPolicy{MaxAttempts: 3},
+			errs:      []error{errors.New("invalid response")},
+			wantCalls: 1,
+			wantErr:   "invalid response",
+		},
+		{
+			name:      "retryable error retried until success",
+			policy:    RetryPolicy{MaxAttempts: 3},
+			errs:      []error{retryable},
+			wantCalls: 2,
+		},
+		{
+			name:      "attempts exhausted",
+			policy:    RetryPolicy{MaxAttempts: 2},
+			errs:      []error{retryable, retryable},
+			wantCalls: 2,
+			wantErr:   "max retries exceeded after 2 attempts",
+		},
+		{
+			name:      "single attempt by default",
+			policy:    RetryPolicy{},
+			errs:      []error{retryable},
+			wantCalls: 1,
+			wantErr:   "max retries exceeded after 1 attempts",
+		},
+		{
+			name:      "delay past the time limit",
+			policy:    RetryPolicy{MaxAttempts: 3, MaxElapsed: 100 * time.Millisecond},
+			errs:      []error{retryable},
+			wantCalls: 1,
+			wantErr:   "retry time limit",
+		},
+		{
+			name:      "retry hint beyond the time limit",
+			policy:    RetryPolicy{MaxAttempts: 3, MaxElapsed: 5 * time.Second},
+			errs:      []error{&HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Minute}},
+			wantCalls: 1,
+			wantErr:   "retry time limit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := tt.policy.Do(context.Background(), func() error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+
+			if calls != tt.wantCalls {
+				t.Errorf("attempts = %d, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Do: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Do error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDoStopsOnCancel(t *testing.T) {
+	t.Run("while waiting", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		calls := 0
+		start := time.Now()
+		err := RetryPolicy{MaxAttempts: 5}.Do(ctx, func() error {
+			calls++
+			return &HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Minute}
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Do error = %v, want context.DeadlineExceeded", err)
+		}
+		if calls != 1 {
+			t.Errorf("attempts = %d, want 1", calls)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Do returned %v after cancellation", elapsed)
+		}
+	})
+
+	t.Run("during an attempt", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		calls := 0
+		err := RetryPolicy{MaxAttempts: 5}.Do(ctx, func() error {
+			calls++
+			cancel()
+			return &HTTPError{StatusCode: http.StatusServiceUnavailable}
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Do error = %v, want context.Canceled", err)
+		}
+		if calls != 1 {
+			t.Errorf("attempts = %d, want 1", calls)
+		}
+	})
+}
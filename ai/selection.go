@@ -0,0 +1,158 @@
+package ai
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+// SetSelectionPolicy sets the quotas and thresholds used to pick articles. Category
+// names are matched case-insensitively against Categories; unknown ones are ignored.
+func (a *Analyzer) SetSelectionPolicy(policy models.SelectionPolicy) {
+	categories := make(map[string]models.CategoryQuota, len(policy.Categories))
+	for name, quota := range policy.Categories {
+		category, ok := canonicalCategory(name)
+		if !ok {
+			log.Printf("Warning: Ignoring selection quota for unknown category %q", name)
+			continue
+		}
+		categories[category] = quota
+	}
+	policy.Categories = categories
+	a.policy = policy
+}
+
+// selection tracks the articles picked so far and the counts the policy caps
+type selection struct {
+	policy     models.SelectionPolicy
+	articles   []models.AnalyzedArticle
+	picked     map[string]bool // By link
+	ruledOut   map[string]bool // By link; caps only tighten, so these stay out
+	byCategory map[string]int
+	bySource   map[string]int
+	topics     []string
+	recorder   DecisionRecorder
+	rank       map[string]int // 1-based position in the ranked list, by link
+}
+
+// selectWithDiversity selects the top N articles under the selection policy. Categories
+// with guaranteed slots are filled first from their best eligible articles, then the
+// remaining slots go to the best articles within the category and source caps. Articles
+// below the minimum score are never selected. The result keeps the ranked order.
+// Every article's outcome is sent to recorder.
+func (a *Analyzer) selectWithDiversity(analyzed []models.AnalyzedArticle, topN int, recorder DecisionRecorder) []models.AnalyzedArticle {
+	s := &selection{
+		policy:     a.policy,
+		articles:   make([]models.AnalyzedArticle, 0, topN),
+		picked:     make(map[string]bool),
+		ruledOut:   make(map[string]bool),
+		byCategory: make(map[string]int),
+		bySource:   make(map[string]int),
+		recorder:   recorder,
+		rank:       make(map[string]int, len(analyzed)),
+	}
+	for i, article := range analyzed {
+		s.rank[article.Link] = i + 1
+	}
+
+	// Filter each article rather than cutting the list, since a personalized ranking is
+	// not ordered by relevance score
+	eligible := make([]models.AnalyzedArticle, 0, len(analyzed))
+	belowMin := 0
+	for _, article := range analyzed {
+		if article.RelevanceScore < a.policy.MinScore {
+			belowMin++
+			recorder.Rejected(article, s.rank[article.Link], fmt.Sprintf("score %.1f is below the minimum of %.1f", article.RelevanceScore, a.policy.MinScore))
+			continue
+		}
+		eligible = append(eligible, article)
+	}
+	if belowMin > 0 {
+		log.Printf("  ⊘ Skipping %d article(s) scored below the minimum of %.1f", belowMin, a.policy.MinScore)
+	}
+
+	// Guaranteed slots, in category order so the choice is deterministic
+	for _, category := range Categories {
+		quota := a.policy.Categories[category]
+		if quota.Min == 0 {
+			continue
+		}
+
+		filled := 0
+		for _, article := range eligible {
+			if filled == quota.Min || len(s.articles) >= topN {
+				break
+			}
+			if article.Category != category || s.ruledOut[article.Link] || !a.tryPick(s, article) {
+				continue
+			}
+			log.Printf("  ★ Guaranteed %s slot %d/%d: '%s' [%.1f]", category, filled+1, quota.Min, article.Title, article.RelevanceScore)
+			recorder.Selected(article, s.rank[article.Link], fmt.Sprintf("guaranteed %s slot %d/%d", category, filled+1, quota.Min))
+			filled++
+		}
+		if filled < quota.Min {
+			log.Printf("  ⚠ Only %d of %d guaranteed %s slot(s) filled - not enough eligible articles", filled, quota.Min, category)
+		}
+	}
+
+	// Remaining slots by rank
+	for _, article := range eligible {
+		if s.picked[article.Link] || s.ruledOut[article.Link] {
+			continue
+		}
+		if len(s.articles) >= topN {
+			recorder.Rejected(article, s.rank[article.Link], fmt.Sprintf("all %d slots were filled by higher-ranked articles", topN))
+			continue
+		}
+		if a.tryPick(s, article) {
+			recorder.Selected(article, s.rank[article.Link], "best remaining article within the caps")
+		}
+	}
+
+	// Present guaranteed picks in ranked order along with the rest
+	sort.SliceStable(s.articles, func(i, j int) bool {
+		return s.rank[s.articles[i].Link] < s.rank[s.articles[j].Link]
+	})
+
+	return s.articles
+}
+
+// tryPick adds the article to the selection unless a cap or a similar selected topic
+// rules it out, logging and recording the reason it was skipped
+func (a *Analyzer) tryPick(s *selection, article models.AnalyzedArticle) bool {
+	if limit := s.policy.MaxFor(article.Category); limit > 0 && s.byCategory[article.Category] >= limit {
+		log.Printf("  ⊘ Skipping '%s' - category '%s' limit reached (%d/%d)",
+			article.Title, article.Category, s.byCategory[article.Category], limit)
+		s.ruleOut(article, fmt.Sprintf("category '%s' limit reached (%d/%d)", article.Category, s.byCategory[article.Category], limit))
+		return false
+	}
+
+	if limit := s.policy.MaxPerSource; limit > 0 && s.bySource[article.Source] >= limit {
+		log.Printf("  ⊘ Skipping '%s' - source '%s' limit reached (%d/%d)",
+			article.Title, article.Source, s.bySource[article.Source], limit)
+		s.ruleOut(article, fmt.Sprintf("source '%s' limit reached (%d/%d)", article.Source, s.bySource[article.Source], limit))
+		return false
+	}
+
+	// Check for duplicate topics (already handled when stories were grouped by embedding)
+	if !a.dedupedByEmbedding && a.isDuplicateTopic(article.Title, s.topics) {
+		log.Printf("  ⊘ Skipping '%s' - similar topic already selected", article.Title)
+		s.ruleOut(article, "a similar topic was already selected")
+		return false
+	}
+
+	s.articles = append(s.articles, article)
+	s.picked[article.Link] = true
+	s.byCategory[article.Category]++
+	s.bySource[article.Source]++
+	s.topics = append(s.topics, article.Title)
+	return true
+}
+
+// ruleOut records why an article can no longer be selected
+func (s *selection) ruleOut(article models.AnalyzedArticle, reason string) {
+	s.ruledOut[article.Link] = true
+	s.recorder.Rejected(article, s.rank[article.Link], reason)
+}
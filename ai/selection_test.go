@@ -0,0 +1,142 @@
+package ai
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+// ranked builds an analyzed article whose title is its link, so titles never look alike
+func ranked(link, category, source string, score float64) models.AnalyzedArticle {
+	return models.AnalyzedArticle{
+		Article:        models.Article{Title: link, Link: link, Source: source},
+		RelevanceScore: score,
+		Category:       category,
+	}
+}
+
+func TestSelectWithDiversity(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   models.SelectionPolicy
+		topN     int
+		articles []models.AnalyzedArticle
+		want     []string
+	}{
+		{
+			name:   "category cap skips to the next category",
+			policy: models.SelectionPolicy{TopN: 3, MaxPerCategory: 2},
+			topN:   3,
+			articles: []models.AnalyzedArticle{
+				ranked("alpha", "Backend", "one", 9),
+				ranked("bravo", "Backend", "two", 8),
+				ranked("charlie", "Backend", "three", 7),
+				ranked("delta", "Security", "four", 6),
+			},
+			want: []string{"alpha", "bravo", "delta"},
+		},
+		{
+			name:   "source cap",
+			policy: models.SelectionPolicy{TopN: 2, MaxPerSource: 1},
+			topN:   2,
+			articles: []models.AnalyzedArticle{
+				ranked("alpha", "Backend", "one", 9),
+				ranked("bravo", "Security", "one", 8),
+				ranked("charlie", "Data", "two", 7),
+			},
+			want: []string{"alpha", "charlie"},
+		},
+		{
+			name:   "minimum score filters a list not sorted by score",
+			policy: models.SelectionPolicy{TopN: 3, MinScore: 5},
+			topN:   3,
+			articles: []models.AnalyzedArticle{
+				ranked("alpha", "Backend", "one", 4),
+				ranked("bravo", "Security", "two", 5.5),
+				ranked("charlie", "Data", "three", 7),
+				ranked("delta", "Cloud", "four", 3),
+				ranked("echo", "Mobile", "five", 6),
+			},
+			want: []string{"bravo", "charlie", "echo"},
+		},
+		{
+			name: "guaranteed slot goes to a lower-ranked category",
+			policy: models.SelectionPolicy{TopN: 2, Categories: map[string]models.CategoryQuota{
+				"Security": {Min: 1},
+			}},
+			topN: 2,
+			articles: []models.AnalyzedArticle{
+				ranked("alpha", "Backend", "one", 9),
+				ranked("bravo", "Backend", "two", 8),
+				ranked("charlie", "Security", "three", 4),
+			},
+			want: []string{"alpha", "charlie"},
+		},
+		{
+			name: "guaranteed slot is filled before the source cap is used up",
+			policy: models.SelectionPolicy{TopN: 2, MaxPerSource: 1, Categories: map[string]models.CategoryQuota{
+				"Data": {Min: 1},
+			}},
+			topN: 2,
+			articles: []models.AnalyzedArticle{
+				ranked("alpha", "Backend", "one", 9),
+				ranked("bravo", "Security", "two", 8),
+				ranked("charlie", "Data", "one", 7),
+			},
+			want: []string{"bravo", "charlie"},
+		},
+		{
+			name: "category max overrides the default cap",
+			policy: models.SelectionPolicy{TopN: 3, MaxPerCategory: 3, Categories: map[string]models.CategoryQuota{
+				"backend": {Max: 1},
+			}},
+			topN: 3,
+			articles: []models.AnalyzedArticle{
+				ranked("alpha", "Backend", "one", 9),
+				ranked("bravo", "Backend", "two", 8),
+				ranked("charlie", "Security", "three", 7),
+				ranked("delta", "Security", "four", 6),
+			},
+			want: []string{"alpha", "charlie", "delta"},
+		},
+		{
+			name: "guaranteed slot below the minimum score stays empty",
+			policy: models.SelectionPolicy{TopN: 2, MinScore: 5, Categories: map[string]models.CategoryQuota{
+				"Security": {Min: 1},
+			}},
+			topN: 2,
+			articles: []models.AnalyzedArticle{
+				ranked("alpha", "Backend", "one", 9),
+				ranked("bravo", "Data", "two", 8),
+				ranked("charlie", "Security", "three", 4),
+			},
+			want: []string{"alpha", "bravo"},
+		},
+		{
+			name:   "fewer articles than slots",
+			policy: models.SelectionPolicy{TopN: 5},
+			topN:   5,
+			articles: []models.AnalyzedArticle{
+				ranked("alpha", "Backend", "one", 9),
+				ranked("bravo", "Data", "two", 8),
+			},
+			want: []string{"alpha", "bravo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewAnalyzer(NewFakeProvider(), &models.Config{Selection: tt.policy})
+			selected := analyzer.selectWithDiversity(tt.articles, tt.topN, nopRecorder{})
+
+			got := make([]string, len(selected))
+			for i, article := range selected {
+				got[i] = article.Link
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selected %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+const (
+	maxSummaryCorrections = 2 // Corrective re-prompts after an over-long or malformed summary
+	summaryBulletCount    = 3
+)
+
+// summaryStyle describes how one summary style is requested and validated
+type summaryStyle struct {
+	task      Task
+	prompt    string
+	schema    *Schema // Set for styles returned as JSON
+	maxLength int     // Character limit of the summary, or of each bullet
+}
+
+// summaryStyles maps each models.SummaryStyles entry to its request settings
+var summaryStyles = map[string]summaryStyle{
+	models.SummaryOneLiner:     {task: TaskSummarize, prompt: promptSummarize, maxLength: 300},
+	models.SummaryBullets:      {task: TaskSummarizeBullets, prompt: promptSummarizeBullets, schema: bulletsSchema, maxLength: 160},
+	models.SummaryWhyItMatters: {task: TaskSummarizeWhy, prompt: promptSummarizeWhy, maxLength: 600},
+}
+
+// bulletsSchema describes the JSON array expected for the bullets style
+var bulletsSchema = &Schema{Type: "array", Items: &Schema{Type: "string"}}
+
+// SetSummaryStyles sets the styles generated for each selected article in addition to
+// the one-liner, which is always generated
+func (a *Analyzer) SetSummaryStyles(styles []string) {
+	a.summaryStyles = nil
+	for _, style := range styles {
+		if style != models.SummaryOneLiner {
+			a.summaryStyles = append(a.summaryStyles, style)
+		}
+	}
+}
+
+// parseSummary validates a summary response for the style, returning the normalized
+// text. Bullets are returned one per line.
+func parseSummary(text string, style summaryStyle) (string, error) {
+	if style.schema == nil {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return "", fmt.Errorf("summary is empty")
+		}
+		if length := len([]rune(text)); length > style.maxLength {
+			return "", fmt.Errorf("summary is %d characters, the limit is %d", length, style.maxLength)
+		}
+		return text, nil
+	}
+
+	var bullets []string
+	if err := json.Unmarshal([]byte(extractJSON(text)), &bullets); err != nil {
+		return "", fmt.Errorf("response is not a JSON array of strings: %w", err)
+	}
+	if len(bullets) != summaryBulletCount {
+		return "", fmt.Errorf("got %d bullets, expected %d", len(bullets), summaryBulletCount)
+	}
+	for i, bullet := range bullets {
+		bullets[i] = flattenText(strings.TrimLeft(bullet, "-•* "), 0)
+		if bullets[i] == "" {
+			return "", fmt.Errorf("bullet %d is empty", i+1)
+		}
+		if length := len([]rune(bullets[i])); length > style.maxLength {
+			return "", fmt.Errorf("bullet %d is %d characters, the limit is %d", i+1, length, style.maxLength)
+		}
+	}
+	return strings.Join(bullets, "\n"), nil
+}
+
+// truncateSummary shortens a summary that stayed over its limit after all corrections,
+// cutting each text at a sentence or word boundary within the limit
+func truncateSummary(text string, style summaryStyle) string {
+	if style.schema != nil {
+		var bullets []string
+		if err := json.Unmarshal([]byte(extractJSON(text)), &bullets); err != nil || len(bullets) == 0 {
+			return ""
+		}
+		for i, bullet := range bullets {
+			bullets[i] = truncateAtBoundary(strings.TrimLeft(bullet, "-•* "), style.maxLength)
+		}
+		return strings.Join(bullets[:min(len(bullets), summaryBulletCount)], "\n")
+	}
+	return truncateAtBoundary(text, style.maxLength)
+}
+
+// truncateAtBoundary shortens text to at most maxLen characters. It keeps the complete
+// sentences that fit, or else cuts after the last whole word and adds an ellipsis. A
+// boundary that would drop more than half the limit falls back to a hard cut.
+func truncateAtBoundary(text string, maxLen int) string {
+	text = flattenText(text, 0)
+	runes := []rune(text)
+	if len(runes) <= maxLen {
+		return text
+	}
+
+	// A sentence may end exactly at the limit, so look one character past it for the space
+	window := string(runes[:maxLen+1])
+	for end := len(window) - 1; end > 0; end-- {
+		if window[end] == ' ' && strings.ContainsRune(".!?", rune(window[end-1])) {
+			if sentences := window[:end]; len([]rune(sentences)) >= maxLen/2 {
+				return sentences
+			}
+			break
+		}
+	}
+
+	// Leave room for the ellipsis
+	cut := string(runes[:maxLen-1])
+	if runes[maxLen-1] != ' ' {
+		if space := strings.LastIndex(cut, " "); space > 0 && len([]rune(cut[:space])) >= maxLen/2 {
+			cut = cut[:space]
+		}
+	}
+	return strings.TrimRight(cut, " ,;:-") + "…"
+}
+
+// applySummary stores a generated summary on the article field for its style
+func applySummary(article *models.AnalyzedArticle, style, summary string) {
+	switch style {
+	case models.SummaryBullets:
+		article.Bullets = strings.Split(summary, "\n")
+	case models.SummaryWhyItMatters:
+		article.WhyItMatters = summary
+	default:
+		article.Summary = summary
+	}
+}
@@ -0,0 +1,185 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+// Small limits keep the fixtures readable
+var (
+	testOneLiner = summaryStyle{maxLength: 40}
+	testBullets  = summaryStyle{schema: bulletsSchema, maxLength: 20}
+)
+
+func TestParseSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		style    summaryStyle
+		response string
+		want     string
+		wantErr  string // Substring of the expected error; empty for success
+	}{
+		{
+			name:     "one-liner trimmed",
+			style:    testOneLiner,
+			response: "  Go 1.24 ships generic type aliases.\n",
+			want:     "Go 1.24 ships generic type aliases.",
+		},
+		{
+			name:     "one-liner at the limit",
+			style:    testOneLiner,
+			response: strings.Repeat("a", 40),
+			want:     strings.Repeat("a", 40),
+		},
+		{
+			name:     "limit counted in characters",
+			style:    testOneLiner,
+			response: strings.Repeat("é", 40),
+			want:     strings.Repeat("é", 40),
+		},
+		{
+			name:     "one-liner over the limit",
+			style:    testOneLiner,
+			response: "Go 1.24 ships generic type aliases and a faster map.",
+			wantErr:  "summary is 52 characters, the limit is 40",
+		},
+		{
+			name:     "empty one-liner",
+			style:    testOneLiner,
+			response: " \n ",
+			wantErr:  "summary is empty",
+		},
+		{
+			name:     "bullets",
+			style:    testBullets,
+			response: `["Faster builds.", "New  vet check.", "Smaller binaries."]`,
+			want:     "Faster builds.\nNew vet check.\nSmaller binaries.",
+		},
+		{
+			name:     "bullet markers and code fence stripped",
+			style:    testBullets,
+			response: "```json\n[\"- Faster builds.\", \"• New vet check.\", \"* Smaller binaries.\"]\n```",
+			want:     "Faster builds.\nNew vet check.\nSmaller binaries.",
+		},
+		{
+			name:     "too few bullets",
+			style:    testBullets,
+			response: `["Faster builds.", "New vet check."]`,
+			wantErr:  "got 2 bullets, expected 3",
+		},
+		{
+			name:     "empty bullet",
+			style:    testBullets,
+			response: `["Faster builds.", " - ", "Smaller binaries."]`,
+			wantErr:  "bullet 2 is empty",
+		},
+		{
+			name:     "bullet over the limit",
+			style:    testBullets,
+			response: `["Faster builds.", "New vet check.", "Binaries are much smaller."]`,
+			wantErr:  "bullet 3 is 26 characters, the limit is 20",
+		},
+		{
+			name:     "bullets as plain text",
+			style:    testBullets,
+			response: "- Faster builds.\n- New vet check.\n- Smaller binaries.",
+			wantErr:  "not a JSON array of strings",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSummary(tt.response, tt.style)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseSummary error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSummary: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parseSummary = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		style    summaryStyle
+		response string
+		want     string
+	}{
+		{
+			name:     "within the limit",
+			style:    testOneLiner,
+			response: "Go 1.24 ships  generic type aliases.",
+			want:     "Go 1.24 ships generic type aliases.",
+		},
+		{
+			name:     "cut after the last whole sentence",
+			style:    testOneLiner,
+			response: "Go 1.24 is out with new features. It ships generic aliases.",
+			want:     "Go 1.24 is out with new features.",
+		},
+		{
+			name:     "sentence ending at the limit kept",
+			style:    testOneLiner,
+			response: "Go 1.24 ships generic aliases for types. More follows.",
+			want:     "Go 1.24 ships generic aliases for types.",
+		},
+		{
+			name:     "cut after the last whole word",
+			style:    testOneLiner,
+			response: "Go 1.24 ships generic type aliases alongside a faster map.",
+			want:     "Go 1.24 ships generic type aliases…",
+		},
+		{
+			name:     "sentence too short to keep",
+			style:    testOneLiner,
+			response: "Big news. Go 1.24 ships generic type aliases and more.",
+			want:     "Big news. Go 1.24 ships generic type…",
+		},
+		{
+			name:     "single long word cut hard",
+			style:    testOneLiner,
+			response: strings.Repeat("x", 50),
+			want:     strings.Repeat("x", 39) + "…",
+		},
+		{
+			name:     "each bullet cut",
+			style:    testBullets,
+			response: `["- Builds are much faster now.", "New vet check.", "Binaries are much smaller."]`,
+			want:     "Builds are much…\nNew vet check.\nBinaries are much…",
+		},
+		{
+			name:     "extra bullets dropped",
+			style:    testBullets,
+			response: `["One.", "Two.", "Three.", "Four."]`,
+			want:     "One.\nTwo.\nThree.",
+		},
+		{
+			name:     "malformed bullets",
+			style:    testBullets,
+			response: `["One.", "Two."`,
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateSummary(tt.response, tt.style)
+			if got != tt.want {
+				t.Errorf("truncateSummary = %q, want %q", got, tt.want)
+			}
+			for _, line := range strings.Split(got, "\n") {
+				if length := len([]rune(line)); length > tt.style.maxLength {
+					t.Errorf("line %q is %d characters, over the limit of %d", line, length, tt.style.maxLength)
+				}
+			}
+		})
+	}
+}
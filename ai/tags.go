@@ -0,0 +1,104 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	maxTagCorrections = 2  // Corrective re-prompts after an invalid tags response
+	maxTags           = 3  // Tags kept per article
+	maxTagLength      = 30 // Longer tags are dropped as sentences rather than keywords
+	fallbackCategory  = "General"
+)
+
+// Categories is the fixed list of categories an article can be assigned to
+var Categories = []string{
+	"AI/ML", "Web Development", "Backend", "DevOps", "Mobile", "Security",
+	"Data", "Cloud", "Open Source", "Career", "General",
+}
+
+// tagsSchema describes the JSON object expected from extractTagsAndCategory
+var tagsSchema = &Schema{
+	Type: "object",
+	Properties: map[string]*Schema{
+		"category": {Type: "string", Enum: Categories},
+		"tags":     {Type: "array", Items: &Schema{Type: "string"}},
+	},
+	Required: []string{"category", "tags"},
+}
+
+// tagStats counts tagging outcomes for a single run
+type tagStats struct {
+	corrections int // Corrective re-prompts sent
+	invalid     int // Articles whose responses never validated
+	fallbacks   int // Articles that fell back to the default category (invalid or provider error)
+}
+
+// tagsResponse is the JSON object returned by the model
+type tagsResponse struct {
+	Category string   `json:"category"`
+	Tags     []string `json:"tags"`
+}
+
+// parseTagsResponse decodes and validates a tags response, returning normalized tags
+// and the canonical category name
+func parseTagsResponse(text string) ([]string, string, error) {
+	var resp tagsResponse
+	if err := json.Unmarshal([]byte(extractJSON(text)), &resp); err != nil {
+		return nil, "", fmt.Errorf("response is not valid JSON: %w", err)
+	}
+
+	category, ok := canonicalCategory(resp.Category)
+	if !ok {
+		return nil, "", fmt.Errorf("category %q is not one of the allowed categories", resp.Category)
+	}
+
+	tags := normalizeTags(resp.Tags)
+	if len(tags) == 0 {
+		return nil, "", fmt.Errorf("no usable tags in %v", resp.Tags)
+	}
+
+	return tags, category, nil
+}
+
+// canonicalCategory matches a category case-insensitively against Categories
+func canonicalCategory(category string) (string, bool) {
+	category = strings.TrimSpace(category)
+	for _, allowed := range Categories {
+		if strings.EqualFold(category, allowed) {
+			return allowed, true
+		}
+	}
+	return "", false
+}
+
+// normalizeTags lowercases, trims and dedupes tags, dropping empty or over-long ones
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool)
+	normalized := make([]string, 0, maxTags)
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.Join(strings.Fields(strings.Trim(tag, "#[]\"'")), " "))
+		if tag == "" || len(tag) > maxTagLength || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+		if len(normalized) == maxTags {
+			break
+		}
+	}
+	return normalized
+}
+
+// correctiveTagsPrompt asks the model to fix its previous response
+func (a *Analyzer) correctiveTagsPrompt(basePrompt, previous string, validationErr error) (string, error) {
+	return a.prompts.render(promptTagsCorrection, PromptData{
+		Prompt:       basePrompt,
+		Previous:     previous,
+		Error:        validationErr.Error(),
+		Categories:   Categories,
+		MaxTagLength: maxTagLength,
+	})
+}
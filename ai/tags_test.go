@@ -0,0 +1,140 @@
+package ai
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+func TestParseTagsResponse(t *testing.T) {
+	tests := []struct {
+		name         string
+		response     string
+		wantTags     []string
+		wantCategory string
+		wantErr      string // Substring of the expected error; empty for success
+	}{
+		{
+			name:         "valid response",
+			response:     `{"category": "Backend", "tags": ["go", "databases"]}`,
+			wantTags:     []string{"go", "databases"},
+			wantCategory: "Backend",
+		},
+		{
+			name:         "category and tags normalized",
+			response:     `{"category": "  web development ", "tags": ["#React", " Server   Components ", "\"CSS\""]}`,
+			wantTags:     []string{"react", "server components", "css"},
+			wantCategory: "Web Development",
+		},
+		{
+			name:         "code fence stripped",
+			response:     "```json\n{\"category\": \"ai/ml\", \"tags\": [\"LLM\"]}\n```",
+			wantTags:     []string{"llm"},
+			wantCategory: "AI/ML",
+		},
+		{
+			name:         "duplicate and empty tags dropped",
+			response:     `{"category": "Security", "tags": ["TLS", "", "tls", "  ", "OpenSSL"]}`,
+			wantTags:     []string{"tls", "openssl"},
+			wantCategory: "Security",
+		},
+		{
+			name:         "over-long tags dropped and extra tags cut",
+			response:     `{"category": "Data", "tags": ["a tag that reads like a whole sentence", "sql", "postgres", "indexes", "vacuum"]}`,
+			wantTags:     []string{"sql", "postgres", "indexes"},
+			wantCategory: "Data",
+		},
+		{
+			name:     "malformed JSON",
+			response: `{"category": "Backend", "tags": ["go"]`,
+			wantErr:  "not valid JSON",
+		},
+		{
+			name:     "plain text",
+			response: `Category: Backend`,
+			wantErr:  "not valid JSON",
+		},
+		{
+			name:     "unknown category",
+			response: `{"category": "Gaming", "tags": ["consoles"]}`,
+			wantErr:  "not one of the allowed categories",
+		},
+		{
+			name:     "missing category",
+			response: `{"tags": ["go"]}`,
+			wantErr:  "not one of the allowed categories",
+		},
+		{
+			name:     "no tags",
+			response: `{"category": "Backend", "tags": []}`,
+			wantErr:  "no usable tags",
+		},
+		{
+			name:     "only unusable tags",
+			response: `{"category": "Backend", "tags": ["", "#", "this tag is far too long to be a keyword"]}`,
+			wantErr:  "no usable tags",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, category, err := parseTagsResponse(tt.response)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseTagsResponse error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTagsResponse: %v", err)
+			}
+			if category != tt.wantCategory {
+				t.Errorf("category = %q, want %q", category, tt.wantCategory)
+			}
+			if !reflect.DeepEqual(tags, tt.wantTags) {
+				t.Errorf("tags = %q, want %q", tags, tt.wantTags)
+			}
+		})
+	}
+}
+
+func TestCanonicalCategory(t *testing.T) {
+	tests := []struct {
+		category string
+		want     string
+		ok       bool
+	}{
+		{category: "DevOps", want: "DevOps", ok: true},
+		{category: "devops", want: "DevOps", ok: true},
+		{category: " OPEN SOURCE ", want: "Open Source", ok: true},
+		{category: "Open-Source", ok: false},
+		{category: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := canonicalCategory(tt.category)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("canonicalCategory(%q) = %q, %v, want %q, %v", tt.category, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestExtractTagsAndCategoryFallsBackAfterCorrections(t *testing.T) {
+	provider := newStubProvider()
+	provider.responses[TaskTags] = `{"category": "Gaming", "tags": ["consoles"]}`
+	analyzer := NewAnalyzer(provider, &models.Config{})
+
+	_, _, err := analyzer.extractTagsAndCategory(context.Background(), models.Article{Title: "A new console"})
+	if err == nil {
+		t.Fatal("extractTagsAndCategory succeeded, want an error after the corrective attempts")
+	}
+	if got, want := provider.callsFor(TaskTags), maxTagCorrections+1; got != want {
+		t.Errorf("tags requests = %d, want %d", got, want)
+	}
+	if analyzer.tagStats.corrections != maxTagCorrections || analyzer.tagStats.invalid != 1 {
+		t.Errorf("tag stats = %+v, want %d corrections and 1 invalid", analyzer.tagStats, maxTagCorrections)
+	}
+}
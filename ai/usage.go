@@ -0,0 +1,131 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+// ErrBudgetExceeded is returned once the daily LLM budget is spent in abort mode
+var ErrBudgetExceeded = errors.New("daily LLM budget exceeded")
+
+// Budget modes: what happens once the daily budget is spent
+const (
+	BudgetDegrade = "degrade" // Skip optional work (extra tagging, personalization)
+	BudgetAbort   = "abort"   // Stop the run
+)
+
+// Usage stages, in reporting order
+var usageStages = []string{"score", "tag", "summarize", "personalize", "intro"}
+
+// usageStage returns the stage a task's usage is reported under
+func usageStage(task Task) string {
+	switch task {
+	case TaskScore, TaskScoreBatch:
+		return "score"
+	case TaskTags:
+		return "tag"
+	case TaskSummarize, TaskSummarizeBullets, TaskSummarizeWhy:
+		return "summarize"
+	default:
+		return string(task)
+	}
+}
+
+// recordUsage adds a response's token usage to its stage's totals
+func (a *Analyzer) recordUsage(task Task, usage Usage) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	stage := usageStage(task)
+	stats, ok := a.usage[stage]
+	if !ok {
+		stats = &models.StageUsage{Stage: stage}
+		a.usage[stage] = stats
+	}
+	stats.Requests++
+	stats.PromptTokens += usage.PromptTokens
+	stats.OutputTokens += usage.OutputTokens
+	stats.CostUSD += a.cost(usage)
+}
+
+// cost returns the estimated price of a request's tokens in USD
+func (a *Analyzer) cost(usage Usage) float64 {
+	return (float64(usage.PromptTokens)*a.inputPrice + float64(usage.OutputTokens)*a.outputPrice) / 1e6
+}
+
+// Usage returns the token usage and cost of the current run, by stage
+func (a *Analyzer) Usage() []models.StageUsage {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	stages := make([]models.StageUsage, 0, len(a.usage))
+	for _, stage := range usageStages {
+		if stats, ok := a.usage[stage]; ok {
+			stages = append(stages, *stats)
+		}
+	}
+	return stages
+}
+
+// SetSpentToday sets the LLM spend of earlier runs today, counted against the daily budget
+func (a *Analyzer) SetSpentToday(usd float64) {
+	a.spentToday = usd
+}
+
+// runCost returns the estimated cost of the current run so far
+func (a *Analyzer) runCost() float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var total float64
+	for _, stats := range a.usage {
+		total += stats.CostUSD
+	}
+	return total
+}
+
+// checkBudget reports whether optional work in the next stage should be skipped
+// because the daily budget is spent. In abort mode it returns ErrBudgetExceeded instead.
+func (a *Analyzer) checkBudget(stage string) (bool, error) {
+	if a.dailyBudget <= 0 {
+		return false, nil
+	}
+
+	spent := a.spentToday + a.runCost()
+	if spent < a.dailyBudget {
+		return false, nil
+	}
+	if a.budgetMode == BudgetAbort {
+		return false, fmt.Errorf("%w before %s: $%.4f spent of $%.4f", ErrBudgetExceeded, stage, spent, a.dailyBudget)
+	}
+
+	log.Printf("  ⚠ Daily LLM budget reached before %s ($%.4f spent of $%.4f)", stage, spent, a.dailyBudget)
+	return true, nil
+}
+
+// logUsage logs the current run's usage by stage
+func (a *Analyzer) logUsage() {
+	stages := a.Usage()
+	if len(stages) == 0 {
+		return
+	}
+
+	var total models.StageUsage
+	log.Printf("LLM usage:")
+	for _, stage := range stages {
+		log.Printf("  %-12s %4d requests, %7d prompt + %6d output tokens, $%.4f",
+			stage.Stage, stage.Requests, stage.PromptTokens, stage.OutputTokens, stage.CostUSD)
+		total.Requests += stage.Requests
+		total.PromptTokens += stage.PromptTokens
+		total.OutputTokens += stage.OutputTokens
+		total.CostUSD += stage.CostUSD
+	}
+	log.Printf("  %-12s %4d requests, %7d prompt + %6d output tokens, $%.4f",
+		"total", total.Requests, total.PromptTokens, total.OutputTokens, total.CostUSD)
+	if a.dailyBudget > 0 {
+		log.Printf("  Daily budget: $%.4f of $%.4f spent (%s mode)", a.spentToday+total.CostUSD, a.dailyBudget, a.budgetMode)
+	}
+}
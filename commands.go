@@ -0,0 +1,335 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ty-e-boyd/thepaper/ai"
+	"github.com/ty-e-boyd/thepaper/config"
+	"github.com/ty-e-boyd/thepaper/database"
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+// usage prints the command-line help
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage:
+  thepaper [--dry-run] [--record FILE | --replay FILE] [--report DIR]
+                                Build and send today's newsletter, optionally
+                                recording or replaying its LLM calls and writing
+                                a selection report
+  thepaper cache purge [--days N]
+                                Delete cached LLM results older than N days
+                                (default: LLM_CACHE_TTL_DAYS)
+  thepaper user profile [--interests TEXT] [--prefer CATEGORIES] [--block CATEGORIES] [--summary-style STYLE] [--frequency daily|weekly] <email>
+                                Show or update a subscriber's interest profile
+                                (categories are comma-separated)
+  thepaper weekly [--dry-run]
+                                Send the weekly roundup of the past week's daily
+                                issues to weekly subscribers
+  thepaper serve [--addr ADDR]
+                                Serve the open-tracking pixel that emails embed
+                                when TRACKING_URL is set (default :8080)
+  thepaper sources health [--all]
+                                List failing, quarantined and empty feeds
+                                with their fetch history
+  thepaper sources add [--category NAME] [--name NAME] [--feed N] [--dry-run] <url>
+                                Find a website's RSS, Atom or JSON feeds and
+                                add one as a source
+  thepaper sources import [--update] [--category NAME] <file.opml>
+                                Add an OPML file's feeds as sources, using
+                                outline groups as categories
+  thepaper sources export [--all] [--out FILE]
+                                Write the active (or all) sources as OPML
+  thepaper eval [--record] [--cassette FILE] [--k N] <dataset.jsonl>
+                                Measure scoring and tagging quality against a
+                                labeled dataset, replaying recorded responses
+                                (--record calls the configured provider instead)
+
+Flags:
+`)
+	flag.PrintDefaults()
+}
+
+// runCommand dispatches a maintenance command
+func runCommand(args []string) {
+	switch args[0] {
+	case "cache":
+		runCacheCommand(args[1:])
+	case "user":
+		runUserCommand(args[1:])
+	case "eval":
+		runEvalCommand(args[1:])
+	case "weekly":
+		runWeeklyCommand(args[1:])
+	case "serve":
+		runServeCommand(args[1:])
+	case "sources":
+		runSourcesCommand(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", args[0])
+		usage()
+		os.Exit(2)
+	}
+}
+
+// runCacheCommand handles "cache" subcommands
+func runCacheCommand(args []string) {
+	if len(args) == 0 || args[0] != "purge" {
+		fmt.Fprintln(os.Stderr, "Usage: thepaper cache purge [--days N]")
+		os.Exit(2)
+	}
+
+	ttl, err := config.CacheTTL()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	fs := flag.NewFlagSet("cache purge", flag.ExitOnError)
+	days := fs.Int("days", int(ttl/(24*time.Hour)), "Delete entries older than this many days")
+	fs.Parse(args[1:])
+
+	connectDatabase()
+	defer database.Close()
+
+	removed, err := database.PurgeLLMCache(time.Duration(*days) * 24 * time.Hour)
+	if err != nil {
+		log.Fatalf("Failed to purge LLM cache: %v", err)
+	}
+	log.Printf("✓ Purged %d cached LLM result(s) older than %d day(s)", removed, *days)
+}
+
+// runUserCommand handles "user" subcommands
+func runUserCommand(args []string) {
+	if len(args) == 0 || args[0] != "profile" {
+		fmt.Fprintln(os.Stderr, "Usage: thepaper user profile [--interests TEXT] [--prefer CATEGORIES] [--block CATEGORIES] [--summary-style STYLE] [--frequency daily|weekly] <email>")
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet("user profile", flag.ExitOnError)
+	interests := fs.String("interests", "", "Free-text description of the user's interests")
+	prefer := fs.String("prefer", "", "Comma-separated categories to rank higher")
+	block := fs.String("block", "", "Comma-separated categories to exclude")
+	summaryStyle := fs.String("summary-style", "", "Summary style: "+strings.Join(models.SummaryStyles, ", ")+"; empty uses the default")
+	frequency := fs.String("frequency", "", "Newsletter frequency: daily or weekly")
+	fs.Parse(args[1:])
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: thepaper user profile [--interests TEXT] [--prefer CATEGORIES] [--block CATEGORIES] [--summary-style STYLE] [--frequency daily|weekly] <email>")
+		os.Exit(2)
+	}
+
+	connectDatabase()
+	defer database.Close()
+
+	user, err := database.GetUserByEmail(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("Failed to find user %s: %v", fs.Arg(0), err)
+	}
+
+	profile := user.Profile()
+	updated, styleUpdated, frequencyUpdated := false, false, false
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "interests":
+			profile.Interests = strings.TrimSpace(*interests)
+			updated = true
+		case "prefer":
+			profile.PreferredCategories = parseCategories(*prefer)
+			updated = true
+		case "block":
+			profile.BlockedCategories = parseCategories(*block)
+			updated = true
+		case "summary-style":
+			styleUpdated = true
+		case "frequency":
+			frequencyUpdated = true
+		}
+	})
+
+	if updated {
+		if err := database.UpdateUserProfile(user.ID, profile); err != nil {
+			log.Fatalf("Failed to update profile: %v", err)
+		}
+		log.Printf("✓ Profile updated for %s", user.Email)
+	}
+	if styleUpdated {
+		style := strings.TrimSpace(*summaryStyle)
+		if style != "" && !models.ValidSummaryStyle(style) {
+			log.Fatalf("Unknown summary style %q, expected one of %s", style, strings.Join(models.SummaryStyles, ", "))
+		}
+		if err := database.UpdateUserSummaryStyle(user.ID, style); err != nil {
+			log.Fatalf("Failed to update summary style: %v", err)
+		}
+		user.SummaryStyle = style
+		log.Printf("✓ Summary style updated for %s", user.Email)
+	}
+	if frequencyUpdated {
+		if *frequency != models.FrequencyDaily && *frequency != models.FrequencyWeekly {
+			log.Fatalf("Unknown frequency %q, expected daily or weekly", *frequency)
+		}
+		if err := database.UpdateUserFrequency(user.ID, *frequency); err != nil {
+			log.Fatalf("Failed to update frequency: %v", err)
+		}
+		user.Frequency = *frequency
+		log.Printf("✓ Frequency updated for %s", user.Email)
+	}
+
+	log.Printf("Profile for %s (%s):", user.Email, user.Name)
+	log.Printf("  Interests: %s", profile.Interests)
+	log.Printf("  Preferred categories: %s", strings.Join(profile.PreferredCategories, ", "))
+	log.Printf("  Blocked categories: %s", strings.Join(profile.BlockedCategories, ", "))
+	if user.SummaryStyle != "" {
+		log.Printf("  Summary style: %s", user.SummaryStyle)
+	} else {
+		log.Printf("  Summary style: default")
+	}
+	if user.IsWeekly() {
+		log.Printf("  Frequency: weekly")
+	} else {
+		log.Printf("  Frequency: daily")
+	}
+}
+
+// runEvalCommand scores and tags a labeled dataset and reports quality metrics. By
+// default responses are replayed from the dataset's cassette so no network is needed;
+// --record calls the configured provider and saves its responses to the cassette.
+func runEvalCommand(args []string) {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+	record := fs.Bool("record", false, "Call the configured provider and record its responses")
+	cassettePath := fs.String("cassette", "", "Recorded responses (default: <dataset>.cassette.jsonl)")
+	k := fs.Int("k", 0, "Number of articles selected for precision@k (default: the selection policy's top_n)")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: thepaper eval [--record] [--cassette FILE] [--k N] <dataset.jsonl>")
+		os.Exit(2)
+	}
+
+	datasetPath := fs.Arg(0)
+	if *cassettePath == "" {
+		*cassettePath = strings.TrimSuffix(datasetPath, ".jsonl") + ".cassette.jsonl"
+	}
+
+	dataset, err := ai.LoadEvalDataset(datasetPath)
+	if err != nil {
+		log.Fatalf("Failed to load dataset: %v", err)
+	}
+
+	cfg, err := config.LoadAI()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if *k <= 0 {
+		*k = cfg.Selection.TopN
+	}
+	prompts, err := ai.LoadPrompts(cfg.PromptsDir)
+	if err != nil {
+		log.Fatalf("Failed to load prompts: %v", err)
+	}
+
+	ctx := context.Background()
+	var provider ai.Provider
+	var cassette *ai.Cassette
+	if *record {
+		live, err := ai.NewProvider(ctx, cfg)
+		if err != nil {
+			log.Fatalf("Failed to create AI provider: %v", err)
+		}
+		cassette = ai.NewCassette()
+		provider = ai.NewRecordingProvider(live, cassette)
+		log.Printf("Recording %s (%s) responses to %s", live.Name(), live.Model(), *cassettePath)
+	} else {
+		cassette, err = ai.LoadCassette(*cassettePath)
+		if err != nil {
+			log.Fatalf("Failed to load cassette (record one with --record): %v", err)
+		}
+		provider = ai.NewReplayProvider(cassette)
+		log.Printf("Replaying %d recorded responses from %s", cassette.Len(), *cassettePath)
+
+		// Replayed responses are instant and a missing one never appears on retry
+		cfg.AIRequestsPerMinute = 0
+		cfg.AITokensPerMinute = 0
+		cfg.AIRetryMaxAttempts = 1
+	}
+
+	analyzer := ai.NewAnalyzer(provider, cfg)
+	defer analyzer.Close()
+	analyzer.SetPrompts(prompts)
+
+	report, err := analyzer.Evaluate(ctx, dataset, *k)
+	if errors.Is(err, ai.ErrUnrecordedPrompt) {
+		log.Fatalf("Evaluation failed: %v\nThe prompts or dataset changed since %s was recorded; re-record with --record", err, *cassettePath)
+	}
+	if err != nil {
+		log.Fatalf("Evaluation failed: %v", err)
+	}
+
+	analyzer.LogStats()
+
+	if *record {
+		if err := cassette.Save(*cassettePath); err != nil {
+			log.Fatalf("Failed to save cassette: %v", err)
+		}
+		log.Printf("✓ Recorded %d responses to %s", cassette.Len(), *cassettePath)
+	}
+
+	correlation := "n/a"
+	if !math.IsNaN(report.RankCorrelation) {
+		correlation = fmt.Sprintf("%.3f", report.RankCorrelation)
+	}
+
+	log.Println("\n============================================================")
+	log.Printf("📏 EVALUATION: %s (%d articles)", datasetPath, report.Articles)
+	log.Println("============================================================")
+	log.Printf("Prompts: %s", analyzer.PromptVersion())
+	log.Printf("Rank correlation (Spearman): %s", correlation)
+	log.Printf("Category accuracy: %.1f%% (%d/%d)", 100*report.CategoryAccuracy, report.CategoryCorrect, report.Articles)
+	if report.TaggedArticles > 0 {
+		log.Printf("Tag overlap (mean Jaccard): %.3f over %d labeled articles", report.TagOverlap, report.TaggedArticles)
+	} else {
+		log.Printf("Tag overlap: n/a (no articles have expected tags)")
+	}
+	log.Printf("Precision@%d: %.1f%% (%d/%d)", report.K, 100*report.PrecisionAtK, report.RelevantSelected, report.K)
+	log.Printf("Errors: %d scoring, %d tagging", report.ScoreErrors, report.TagErrors)
+}
+
+// parseCategories splits a comma-separated category list, exiting on unknown categories
+func parseCategories(list string) []string {
+	var categories []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		found := false
+		for _, category := range ai.Categories {
+			if strings.EqualFold(name, category) {
+				categories = append(categories, category)
+				found = true
+				break
+			}
+		}
+		if !found {
+			log.Fatalf("Unknown category %q (allowed: %s)", name, strings.Join(ai.Categories, ", "))
+		}
+	}
+	return categories
+}
+
+// connectDatabase connects to the database and runs migrations, exiting on failure
+func connectDatabase() {
+	log.Println("Connecting to database...")
+	if err := database.Connect(); err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	if err := database.AutoMigrate(); err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
+	}
+}
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ty-e-boyd/thepaper/models"
@@ -11,19 +14,63 @@ import (
 
 // Load reads configuration from environment variables
 func Load() (*models.Config, error) {
-	geminiKey := os.Getenv("GEMINI_API_KEY")
-	if geminiKey == "" {
+	cfg, err := LoadAI()
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.GeminiAPIKey == "" && cfg.AIProvider == "gemini" {
 		return nil, fmt.Errorf("GEMINI_API_KEY environment variable is required")
 	}
 
-	sendgridKey := os.Getenv("SENDGRID_API_KEY")
-	if sendgridKey == "" {
-		return nil, fmt.Errorf("SENDGRID_API_KEY environment variable is required")
+	if err := loadSender(cfg); err != nil {
+		return nil, err
 	}
 
-	fromEmail := os.Getenv("FROM_EMAIL")
-	if fromEmail == "" {
-		return nil, fmt.Errorf("FROM_EMAIL environment variable is required")
+	return cfg, nil
+}
+
+// LoadEmail reads only the email settings, for commands that make no LLM calls
+func LoadEmail() (*models.Config, error) {
+	summaryStyle, err := SummaryStyle()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &models.Config{SummaryStyle: summaryStyle}
+	if err := loadSender(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadSender reads the required SendGrid credentials and sender address into cfg
+func loadSender(cfg *models.Config) error {
+	cfg.SendGridAPIKey = os.Getenv("SENDGRID_API_KEY")
+	if cfg.SendGridAPIKey == "" {
+		return fmt.Errorf("SENDGRID_API_KEY environment variable is required")
+	}
+
+	cfg.FromEmail = os.Getenv("FROM_EMAIL")
+	if cfg.FromEmail == "" {
+		return fmt.Errorf("FROM_EMAIL environment variable is required")
+	}
+
+	return nil
+}
+
+// LoadAI reads only the LLM settings, for commands that send no email. Provider
+// credentials are not required here; creating the provider fails without them.
+func LoadAI() (*models.Config, error) {
+	// Optional: LLM provider (gemini, openai or fake; default gemini)
+	aiProvider := os.Getenv("AI_PROVIDER")
+	if aiProvider == "" {
+		aiProvider = "gemini"
+	}
+	switch aiProvider {
+	case "gemini", "openai", "fake":
+	default:
+		return nil, fmt.Errorf("AI_PROVIDER must be one of gemini, openai or fake, got %q", aiProvider)
 	}
 
 	// Optional: rate limit delay in milliseconds (default 200ms for paid tier)
@@ -36,10 +83,290 @@ func Load() (*models.Config, error) {
 		rateLimitMs = parsed
 	}
 
+	// Optional: requests per minute (defaults to the rate implied by GEMINI_RATE_LIMIT_MS)
+	defaultRPM := 0
+	if rateLimitMs > 0 {
+		defaultRPM = 60000 / rateLimitMs
+	}
+	requestsPerMinute, err := intFromEnv("AI_REQUESTS_PER_MINUTE", defaultRPM)
+	if err != nil {
+		return nil, err
+	}
+
+	// Optional: estimated tokens per minute (default 0, i.e. unlimited)
+	tokensPerMinute, err := intFromEnv("AI_TOKENS_PER_MINUTE", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Optional: number of concurrent LLM requests (default 4)
+	concurrency, err := intFromEnv("AI_CONCURRENCY", 4)
+	if err != nil {
+		return nil, err
+	}
+
+	// Optional: retry limits for transient LLM errors (default 6 attempts within 120 seconds)
+	retryMaxAttempts, err := intFromEnv("AI_RETRY_MAX_ATTEMPTS", 6)
+	if err != nil {
+		return nil, err
+	}
+	retryMaxElapsedSeconds, err := intFromEnv("AI_RETRY_MAX_ELAPSED_SECONDS", 120)
+	if err != nil {
+		return nil, err
+	}
+
+	// Optional: embedding similarity at which articles count as the same story (default 0, disabled,
+	// since it adds embedding calls to every run; 0.85 works well)
+	dedupThreshold, err := floatFromEnv("DEDUP_SIMILARITY_THRESHOLD", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Optional: score bonus per doubling of other sources covering a story (default 0.5)
+	coverageBoost, err := floatFromEnv("COVERAGE_BOOST", 0.5)
+	if err != nil {
+		return nil, err
+	}
+
+	// Optional: number of articles scored per LLM call (default 1, i.e. no batching)
+	scoreBatchSize, err := intFromEnv("SCORE_BATCH_SIZE", 1)
+	if err != nil {
+		return nil, err
+	}
+
+	// Optional: token prices in USD per million tokens (default: gemini-2.0-flash list prices)
+	priceInput, err := floatFromEnv("AI_PRICE_INPUT_PER_MTOK", 0.10)
+	if err != nil {
+		return nil, err
+	}
+	priceOutput, err := floatFromEnv("AI_PRICE_OUTPUT_PER_MTOK", 0.40)
+	if err != nil {
+		return nil, err
+	}
+
+	// Optional: daily LLM budget in USD (default 0, i.e. unlimited) and what to do once spent
+	dailyBudget, err := floatFromEnv("AI_DAILY_BUDGET_USD", 0)
+	if err != nil {
+		return nil, err
+	}
+	budgetMode := os.Getenv("AI_BUDGET_MODE")
+	if budgetMode == "" {
+		budgetMode = "degrade"
+	}
+	if budgetMode != "degrade" && budgetMode != "abort" {
+		return nil, fmt.Errorf("AI_BUDGET_MODE must be degrade or abort, got %q", budgetMode)
+	}
+
+	summaryStyle, err := SummaryStyle()
+	if err != nil {
+		return nil, err
+	}
+
+	// Optional: fetch selected articles' pages for summarizing (default true), capped at
+	// EXTRACT_MAX_CHARS characters (default 8000)
+	extractFullText := true
+	if value := os.Getenv("EXTRACT_FULL_TEXT"); value != "" {
+		extractFullText, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("EXTRACT_FULL_TEXT must be true or false: %w", err)
+		}
+	}
+	extractMaxChars, err := intFromEnv("EXTRACT_MAX_CHARS", 8000)
+	if err != nil {
+		return nil, err
+	}
+
+	// Optional: selection policy file (JSON; default 8 articles, at most 2 per category)
+	selection, err := LoadSelectionPolicy(os.Getenv("SELECTION_POLICY_FILE"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Optional: how raw scores are normalized (percentile, zscore or off; default percentile)
+	// and how many days of earlier scores they are compared against (default 30)
+	scoreCalibration := os.Getenv("SCORE_CALIBRATION")
+	if scoreCalibration == "" {
+		scoreCalibration = "percentile"
+	}
+	if scoreCalibration != "percentile" && scoreCalibration != "zscore" && scoreCalibration != "off" {
+		return nil, fmt.Errorf("SCORE_CALIBRATION must be percentile, zscore or off, got %q", scoreCalibration)
+	}
+	scoreHistoryDays, err := intFromEnv("SCORE_HISTORY_DAYS", 30)
+	if err != nil {
+		return nil, err
+	}
+	if scoreHistoryDays < 1 {
+		return nil, fmt.Errorf("SCORE_HISTORY_DAYS must be at least 1, got %d", scoreHistoryDays)
+	}
+
+	cacheTTL, err := CacheTTL()
+	if err != nil {
+		return nil, err
+	}
+
 	return &models.Config{
-		GeminiAPIKey:    geminiKey,
-		SendGridAPIKey:  sendgridKey,
-		FromEmail:       fromEmail,
-		GeminiRateLimit: time.Duration(rateLimitMs) * time.Millisecond,
+		GeminiAPIKey:         os.Getenv("GEMINI_API_KEY"),
+		GeminiRateLimit:      time.Duration(rateLimitMs) * time.Millisecond,
+		AIProvider:           aiProvider,
+		AIModel:              os.Getenv("AI_MODEL"),
+		OpenAIBaseURL:        os.Getenv("OPENAI_BASE_URL"),
+		OpenAIAPIKey:         os.Getenv("OPENAI_API_KEY"),
+		ScoreBatchSize:       scoreBatchSize,
+		LLMCacheTTL:          cacheTTL,
+		AIRequestsPerMinute:  requestsPerMinute,
+		AITokensPerMinute:    tokensPerMinute,
+		AIConcurrency:        concurrency,
+		AIRetryMaxAttempts:   retryMaxAttempts,
+		AIRetryMaxElapsed:    time.Duration(retryMaxElapsedSeconds) * time.Second,
+		AIEmbeddingModel:     os.Getenv("AI_EMBEDDING_MODEL"),
+		DedupThreshold:       dedupThreshold,
+		CoverageBoost:        coverageBoost,
+		PromptsDir:           os.Getenv("PROMPTS_DIR"),
+		AIPriceInputPerMTok:  priceInput,
+		AIPriceOutputPerMTok: priceOutput,
+		AIDailyBudgetUSD:     dailyBudget,
+		AIBudgetMode:         budgetMode,
+		SummaryStyle:         summaryStyle,
+		ExtractFullText:      extractFullText,
+		ExtractMaxChars:      extractMaxChars,
+		Selection:            selection,
+		ScoreCalibration:     scoreCalibration,
+		ScoreHistoryDays:     scoreHistoryDays,
 	}, nil
 }
+
+// LoadSelectionPolicy reads a JSON selection policy. Omitted fields keep their default
+// values, and an empty path returns the default policy.
+func LoadSelectionPolicy(path string) (models.SelectionPolicy, error) {
+	policy := models.DefaultSelectionPolicy()
+	if path == "" {
+		return policy, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return policy, fmt.Errorf("failed to read selection policy: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&policy); err != nil {
+		return policy, fmt.Errorf("invalid selection policy %s: %w", path, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return policy, fmt.Errorf("invalid selection policy %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// CacheTTL reads how long cached LLM results stay valid (LLM_CACHE_TTL_DAYS, default 7).
+// A TTL of zero disables the cache.
+func CacheTTL() (time.Duration, error) {
+	days := 7
+	if daysStr := os.Getenv("LLM_CACHE_TTL_DAYS"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil {
+			return 0, fmt.Errorf("LLM_CACHE_TTL_DAYS must be a number: %w", err)
+		}
+		days = parsed
+	}
+	return time.Duration(days) * 24 * time.Hour, nil
+}
+
+// LoadFetch reads the feed download settings
+func LoadFetch() (models.FetchConfig, error) {
+	// Optional: seconds allowed per feed request (default 30) and largest feed in MB (default 10)
+	timeoutSeconds, err := intFromEnv("FETCH_TIMEOUT_SECONDS", 30)
+	if err != nil {
+		return models.FetchConfig{}, err
+	}
+	maxFeedMB, err := intFromEnv("FETCH_MAX_FEED_MB", 10)
+	if err != nil {
+		return models.FetchConfig{}, err
+	}
+
+	// Optional: feeds fetched at once (default 10), requests at once per host (default 2)
+	// and milliseconds between requests to one host (default 500)
+	concurrency, err := intFromEnv("FETCH_CONCURRENCY", 10)
+	if err != nil {
+		return models.FetchConfig{}, err
+	}
+	perHost, err := intFromEnv("FETCH_PER_HOST", 2)
+	if err != nil {
+		return models.FetchConfig{}, err
+	}
+	hostDelayMs, err := intFromEnv("FETCH_HOST_DELAY_MS", 500)
+	if err != nil {
+		return models.FetchConfig{}, err
+	}
+
+	if timeoutSeconds < 1 || maxFeedMB < 1 || concurrency < 1 || perHost < 1 {
+		return models.FetchConfig{}, fmt.Errorf("FETCH_TIMEOUT_SECONDS, FETCH_MAX_FEED_MB, FETCH_CONCURRENCY and FETCH_PER_HOST must be at least 1")
+	}
+	if hostDelayMs < 0 {
+		return models.FetchConfig{}, fmt.Errorf("FETCH_HOST_DELAY_MS must not be negative, got %d", hostDelayMs)
+	}
+
+	return models.FetchConfig{
+		Timeout:      time.Duration(timeoutSeconds) * time.Second,
+		MaxBodyBytes: int64(maxFeedMB) << 20,
+		UserAgent:    os.Getenv("FETCH_USER_AGENT"),
+		Concurrency:  concurrency,
+		PerHost:      perHost,
+		HostDelay:    time.Duration(hostDelayMs) * time.Millisecond,
+	}, nil
+}
+
+// SourceMaxFailures reads how many consecutive failed fetches deactivate a source
+// (SOURCE_MAX_FAILURES, default 5). Zero never deactivates sources.
+func SourceMaxFailures() (int, error) {
+	maxFailures, err := intFromEnv("SOURCE_MAX_FAILURES", 5)
+	if err != nil {
+		return 0, err
+	}
+	if maxFailures < 0 {
+		return 0, fmt.Errorf("SOURCE_MAX_FAILURES must not be negative, got %d", maxFailures)
+	}
+	return maxFailures, nil
+}
+
+// SummaryStyle reads the default summary style (SUMMARY_STYLE: one-liner, bullets or
+// why-it-matters; default one-liner)
+func SummaryStyle() (string, error) {
+	summaryStyle := os.Getenv("SUMMARY_STYLE")
+	if summaryStyle == "" {
+		summaryStyle = models.SummaryOneLiner
+	}
+	if !models.ValidSummaryStyle(summaryStyle) {
+		return "", fmt.Errorf("SUMMARY_STYLE must be one of %s, got %q", strings.Join(models.SummaryStyles, ", "), summaryStyle)
+	}
+	return summaryStyle, nil
+}
+
+// intFromEnv reads an optional integer environment variable, returning def when unset
+func intFromEnv(name string, def int) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return def, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number: %w", name, err)
+	}
+	return parsed, nil
+}
+
+// floatFromEnv reads an optional decimal environment variable, returning def when unset
+func floatFromEnv(name string, def float64) (float64, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return def, nil
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number: %w", name, err)
+	}
+	return parsed, nil
+}
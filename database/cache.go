@@ -0,0 +1,80 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ty-e-boyd/thepaper/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LLMCache is a database-backed cache of LLM results with a time-to-live
+type LLMCache struct {
+	ttl time.Duration
+}
+
+// NewLLMCache creates a cache whose entries expire after ttl
+func NewLLMCache(ttl time.Duration) *LLMCache {
+	return &LLMCache{ttl: ttl}
+}
+
+// Get returns the cached response for key if a fresh entry exists
+func (c *LLMCache) Get(key models.LLMCacheKey) (string, bool, error) {
+	entry, err := GetLLMCacheEntry(key, c.ttl)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return entry.Response, true, nil
+}
+
+// Put stores the response for key, replacing any previous entry
+func (c *LLMCache) Put(key models.LLMCacheKey, response string) error {
+	return SaveLLMCacheEntry(key, response)
+}
+
+// GetLLMCacheEntry finds a cache entry for key created within maxAge
+func GetLLMCacheEntry(key models.LLMCacheKey, maxAge time.Duration) (*LLMCacheEntry, error) {
+	var entry LLMCacheEntry
+	result := DB.Where("article_url = ? AND content_hash = ? AND task = ? AND prompt_version = ? AND model = ? AND created_at > ?",
+		key.URL, key.ContentHash, key.Task, key.PromptVersion, key.Model, time.Now().Add(-maxAge)).First(&entry)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get cache entry: %w", result.Error)
+	}
+	return &entry, nil
+}
+
+// SaveLLMCacheEntry creates or refreshes the cache entry for key
+func SaveLLMCacheEntry(key models.LLMCacheKey, response string) error {
+	entry := &LLMCacheEntry{
+		ArticleURL:    key.URL,
+		ContentHash:   key.ContentHash,
+		Task:          key.Task,
+		PromptVersion: key.PromptVersion,
+		Model:         key.Model,
+		Response:      response,
+		CreatedAt:     time.Now(),
+	}
+
+	result := DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "article_url"}, {Name: "content_hash"}, {Name: "task"}, {Name: "prompt_version"}, {Name: "model"}},
+		DoUpdates: clause.AssignmentColumns([]string{"response", "created_at"}),
+	}).Create(entry)
+	if result.Error != nil {
+		return fmt.Errorf("failed to save cache entry: %w", result.Error)
+	}
+	return nil
+}
+
+// PurgeLLMCache deletes cache entries older than maxAge and returns how many were removed
+func PurgeLLMCache(maxAge time.Duration) (int64, error) {
+	result := DB.Where("created_at <= ?", time.Now().Add(-maxAge)).Delete(&LLMCacheEntry{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge cache: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
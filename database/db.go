@@ -40,7 +40,11 @@ func AutoMigrate() error {
 		&Source{},
 		&EmailSent{},
 		&EmailArticle{},
+		&EmailArticleLink{},
 		&UserEmail{},
+		&LLMCacheEntry{},
+		&RunStat{},
+		&ScoreSample{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
@@ -4,16 +4,22 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/ty-e-boyd/thepaper/models"
 )
 
-// CreateEmailSent creates a new email sent record
-func CreateEmailSent(subject string, totalArticlesAnalyzed, totalSources, recipientCount int) (*EmailSent, error) {
+// CreateEmailSent creates a new email sent record. openTracking is set when the emails
+// carry a tracking pixel.
+func CreateEmailSent(subject string, totalArticlesAnalyzed, totalSources, recipientCount int, editorial models.Editorial, openTracking bool) (*EmailSent, error) {
 	email := &EmailSent{
 		Subject:               subject,
 		SentAt:                time.Now(),
 		TotalArticlesAnalyzed: totalArticlesAnalyzed,
 		TotalSources:          totalSources,
 		RecipientCount:        recipientCount,
+		Intro:                 editorial.Intro,
+		Teaser:                editorial.Teaser,
+		OpenTracking:          openTracking,
 	}
 
 	result := DB.Create(email)
@@ -24,25 +30,59 @@ func CreateEmailSent(subject string, totalArticlesAnalyzed, totalSources, recipi
 	return email, nil
 }
 
-// CreateEmailArticle creates a record of an article included in an email
-func CreateEmailArticle(emailID uint, url, title, source string, relevanceScore float64, category string, tags []string, summary string, publishedAt time.Time, position int) (*EmailArticle, error) {
+// CreateWeeklyEmailSent creates a record of a weekly roundup
+func CreateWeeklyEmailSent(subject string, totalArticles, totalSources, recipientCount int, openTracking bool) (*EmailSent, error) {
+	email := &EmailSent{
+		Subject:               subject,
+		SentAt:                time.Now(),
+		TotalArticlesAnalyzed: totalArticles,
+		TotalSources:          totalSources,
+		RecipientCount:        recipientCount,
+		Weekly:                true,
+		OpenTracking:          openTracking,
+	}
+
+	result := DB.Create(email)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to create weekly email record: %w", result.Error)
+	}
+
+	return email, nil
+}
+
+// CreateEmailArticle creates a record of an article included in an email. userID is
+// set for an article personalized for one subscriber and nil for the shared selection.
+func CreateEmailArticle(emailID uint, userID *uint, url, title, source string, relevanceScore, rawScore float64, category string, tags []string, summary string, bullets []string, whyItMatters string, publishedAt time.Time, position int, promptVersion string) (*EmailArticle, error) {
 	// Encode tags as JSON
 	tagsJSON, err := json.Marshal(tags)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode tags: %w", err)
 	}
 
+	var bulletsJSON []byte
+	if len(bullets) > 0 {
+		bulletsJSON, err = json.Marshal(bullets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode summary bullets: %w", err)
+		}
+	}
+
 	article := &EmailArticle{
 		EmailID:        emailID,
+		UserID:         userID,
 		ArticleURL:     url,
 		ArticleTitle:   title,
 		ArticleSource:  source,
 		RelevanceScore: relevanceScore,
+		RawScore:       rawScore,
 		Category:       category,
 		Tags:           string(tagsJSON),
 		Summary:        summary,
+		SummaryBullets: string(bulletsJSON),
+		WhyItMatters:   whyItMatters,
 		PublishedAt:    publishedAt,
 		Position:       position,
+		PromptVersion:  promptVersion,
 	}
 
 	result := DB.Create(article)
@@ -53,6 +93,39 @@ func CreateEmailArticle(emailID uint, url, title, source string, relevanceScore
 	return article, nil
 }
 
+// CreateEmailArticleLinks records other sources' coverage of an email article's story
+func CreateEmailArticleLinks(emailArticleID uint, related []models.RelatedArticle) error {
+	if len(related) == 0 {
+		return nil
+	}
+
+	links := make([]EmailArticleLink, len(related))
+	for i, article := range related {
+		links[i] = EmailArticleLink{
+			EmailArticleID: emailArticleID,
+			URL:            article.Link,
+			Title:          article.Title,
+			Source:         article.Source,
+		}
+	}
+
+	result := DB.Create(&links)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create email article links: %w", result.Error)
+	}
+	return nil
+}
+
+// GetEmailArticleLinks retrieves the related coverage recorded for an email article
+func GetEmailArticleLinks(emailArticleID uint) ([]EmailArticleLink, error) {
+	var links []EmailArticleLink
+	result := DB.Where("email_article_id = ?", emailArticleID).Order("id").Find(&links)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get email article links: %w", result.Error)
+	}
+	return links, nil
+}
+
 // CreateUserEmail records that an email was sent to a user
 func CreateUserEmail(userID, emailID uint) (*UserEmail, error) {
 	userEmail := &UserEmail{
@@ -70,12 +143,15 @@ func CreateUserEmail(userID, emailID uint) (*UserEmail, error) {
 	return userEmail, nil
 }
 
-// GetRecentEmailArticles returns articles sent in the last N days to prevent duplicates
+// GetRecentEmailArticles returns articles sent in daily issues in the last N days, to
+// prevent duplicates. Weekly roundups only repeat daily articles, so they are left out.
 func GetRecentEmailArticles(days int) ([]EmailArticle, error) {
 	var articles []EmailArticle
 	cutoff := time.Now().AddDate(0, 0, -days)
 
-	result := DB.Where("created_at > ?", cutoff).Find(&articles)
+	result := DB.Joins("JOIN emails_sent ON emails_sent.id = email_articles.email_id").
+		Where("email_articles.created_at > ? AND NOT emails_sent.weekly", cutoff).
+		Find(&articles)
 	if result.Error != nil {
 		return nil, fmt.Errorf("failed to get recent email articles: %w", result.Error)
 	}
@@ -83,6 +159,51 @@ func GetRecentEmailArticles(days int) ([]EmailArticle, error) {
 	return articles, nil
 }
 
+// GetRecentIssueArticles returns the shared selection of daily issues sent in the last N
+// days, for weekly roundups. Issues that reached no subscriber, such as dry runs, and
+// subscribers' personalized picks are left out.
+func GetRecentIssueArticles(days int) ([]EmailArticle, error) {
+	var articles []EmailArticle
+	cutoff := time.Now().AddDate(0, 0, -days)
+
+	result := DB.Joins("JOIN emails_sent ON emails_sent.id = email_articles.email_id").
+		Where("email_articles.created_at > ? AND NOT emails_sent.weekly AND email_articles.user_id IS NULL", cutoff).
+		Where("EXISTS (SELECT 1 FROM user_emails WHERE user_emails.email_id = emails_sent.id)").
+		Find(&articles)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get recent issue articles: %w", result.Error)
+	}
+
+	return articles, nil
+}
+
+// GetEmailOpenRates returns the share of recipients who opened each of the given emails.
+// Emails sent without open tracking, or without recorded recipients, are left out.
+func GetEmailOpenRates(emailIDs []uint) (map[uint]float64, error) {
+	var rows []struct {
+		EmailID uint
+		Sent    int
+		Opened  int
+	}
+	result := DB.Model(&UserEmail{}).
+		Select("user_emails.email_id, COUNT(*) AS sent, SUM(CASE WHEN user_emails.opened THEN 1 ELSE 0 END) AS opened").
+		Joins("JOIN emails_sent ON emails_sent.id = user_emails.email_id").
+		Where("user_emails.email_id IN ? AND emails_sent.open_tracking", emailIDs).
+		Group("user_emails.email_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get email open rates: %w", result.Error)
+	}
+
+	rates := make(map[uint]float64, len(rows))
+	for _, row := range rows {
+		if row.Sent > 0 {
+			rates[row.EmailID] = float64(row.Opened) / float64(row.Sent)
+		}
+	}
+	return rates, nil
+}
+
 // GetRecentArticleURLs returns a map of article URLs sent in the last N days
 func GetRecentArticleURLs(days int) (map[string]bool, error) {
 	articles, err := GetRecentEmailArticles(days)
@@ -98,6 +219,31 @@ func GetRecentArticleURLs(days int) (map[string]bool, error) {
 	return urlMap, nil
 }
 
+// Analyzed converts a sent article back into an analyzed article for re-sending
+func (a EmailArticle) Analyzed() models.AnalyzedArticle {
+	article := models.AnalyzedArticle{
+		Article: models.Article{
+			Title:     a.ArticleTitle,
+			Link:      a.ArticleURL,
+			Source:    a.ArticleSource,
+			Published: a.PublishedAt,
+		},
+		RelevanceScore: a.RelevanceScore,
+		RawScore:       a.RawScore,
+		Summary:        a.Summary,
+		Category:       a.Category,
+		WhyItMatters:   a.WhyItMatters,
+		Selected:       true,
+	}
+	if a.Tags != "" {
+		json.Unmarshal([]byte(a.Tags), &article.Tags)
+	}
+	if a.SummaryBullets != "" {
+		json.Unmarshal([]byte(a.SummaryBullets), &article.Bullets)
+	}
+	return article
+}
+
 // GetEmailByID retrieves an email record by its ID
 func GetEmailByID(emailID uint) (*EmailSent, error) {
 	var email EmailSent
@@ -128,6 +274,16 @@ func GetUserEmails(userID uint) ([]UserEmail, error) {
 	return userEmails, nil
 }
 
+// GetUserEmail retrieves the record of an email sent to a user
+func GetUserEmail(userID, emailID uint) (*UserEmail, error) {
+	var userEmail UserEmail
+	result := DB.Where("user_id = ? AND email_id = ?", userID, emailID).First(&userEmail)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get user email: %w", result.Error)
+	}
+	return &userEmail, nil
+}
+
 // MarkEmailOpened marks an email as opened by a user
 func MarkEmailOpened(userEmailID uint) error {
 	now := time.Now()
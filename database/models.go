@@ -8,26 +8,40 @@ import (
 
 // User represents a subscriber to the newsletter
 type User struct {
-	ID               uint   `gorm:"primaryKey"`
-	Email            string `gorm:"uniqueIndex;not null"`
-	Name             string // Optional field, nullable
-	Subscribed       bool   `gorm:"default:true"`
-	UnsubscribeToken string `gorm:"uniqueIndex;not null"`
-	CreatedAt        time.Time
-	UpdatedAt        time.Time
-	DeletedAt        gorm.DeletedAt `gorm:"index"`
+	ID                  uint   `gorm:"primaryKey"`
+	Email               string `gorm:"uniqueIndex;not null"`
+	Name                string // Optional field, nullable
+	Subscribed          bool   `gorm:"default:true"`
+	UnsubscribeToken    string `gorm:"uniqueIndex;not null"`
+	Interests           string `gorm:"type:text"` // Free-text interests for personalized selection
+	PreferredCategories string // JSON encoded array
+	BlockedCategories   string // JSON encoded array
+	SummaryStyle        string // Preferred summary style; empty uses the SUMMARY_STYLE default
+	Frequency           string `gorm:"default:daily"` // "daily" or "weekly"
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	DeletedAt           gorm.DeletedAt `gorm:"index"`
 }
 
 // Source represents an RSS feed source
 type Source struct {
-	ID        uint   `gorm:"primaryKey"`
-	Name      string `gorm:"not null"`
-	Category  string `gorm:"not null;index"`
-	URL       string `gorm:"uniqueIndex;not null"`
-	Active    bool   `gorm:"default:true"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt gorm.DeletedAt `gorm:"index"`
+	ID                  uint       `gorm:"primaryKey"`
+	Name                string     `gorm:"not null"`
+	Category            string     `gorm:"not null;index"`
+	URL                 string     `gorm:"uniqueIndex;not null"`
+	Active              bool       `gorm:"default:true"`
+	ETag                string     `gorm:"column:etag"` // From the last successful fetch, sent as If-None-Match
+	LastModified        string     // From the last successful fetch, sent as If-Modified-Since
+	LastSuccessAt       *time.Time // Last fetch that returned the feed or 304 Not Modified
+	LastErrorAt         *time.Time
+	LastError           string
+	ConsecutiveFailures int        `gorm:"default:0"`
+	LastItemCount       int        // Items in the feed the last time it was downloaded
+	AvgLatencyMs        float64    // Moving average of successful fetch times
+	QuarantinedAt       *time.Time // Set when the source was deactivated after a failure streak
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	DeletedAt           gorm.DeletedAt `gorm:"index"`
 }
 
 // EmailSent represents an email that was sent out
@@ -38,6 +52,10 @@ type EmailSent struct {
 	TotalArticlesAnalyzed int
 	TotalSources          int
 	RecipientCount        int
+	Intro                 string `gorm:"type:text"` // Editorial intro shown above the articles
+	Teaser                string // Subject teaser; empty if the fixed subject was used
+	Weekly                bool   `gorm:"default:false;index"` // Weekly roundup rather than a daily issue
+	OpenTracking          bool   `gorm:"default:false"`       // Sent with a tracking pixel, so user_emails record opens
 	CreatedAt             time.Time
 	UpdatedAt             time.Time
 }
@@ -46,19 +64,35 @@ type EmailSent struct {
 type EmailArticle struct {
 	ID             uint   `gorm:"primaryKey"`
 	EmailID        uint   `gorm:"not null;index"`
+	UserID         *uint  `gorm:"index"` // Subscriber the article was personalized for; nil for the shared selection
 	ArticleURL     string `gorm:"not null;index"`
 	ArticleTitle   string `gorm:"not null"`
 	ArticleSource  string
-	RelevanceScore float64 `gorm:"type:decimal(3,1)"`
+	RelevanceScore float64 `gorm:"type:decimal(3,1)"` // Calibrated score, comparable across days
+	RawScore       float64 `gorm:"type:decimal(3,1)"` // Score from the LLM before calibration; 0 for rows saved before calibration
 	Category       string
 	Tags           string    // JSON encoded array
 	Summary        string    `gorm:"type:text"`
+	SummaryBullets string    `gorm:"type:text"` // JSON encoded array; empty unless the bullets style was generated
+	WhyItMatters   string    `gorm:"type:text"`
 	PublishedAt    time.Time `gorm:"index"`
 	Position       int       // Position in the email (1-8)
+	PromptVersion  string    // Prompt templates the article was scored, tagged and summarized with
 	CreatedAt      time.Time
 	Email          EmailSent `gorm:"foreignKey:EmailID;constraint:OnDelete:CASCADE"`
 }
 
+// EmailArticleLink records another source's coverage of the same story as an EmailArticle
+type EmailArticleLink struct {
+	ID             uint   `gorm:"primaryKey"`
+	EmailArticleID uint   `gorm:"not null;index"`
+	URL            string `gorm:"not null"`
+	Title          string
+	Source         string
+	CreatedAt      time.Time
+	EmailArticle   EmailArticle `gorm:"foreignKey:EmailArticleID;constraint:OnDelete:CASCADE"`
+}
+
 // UserEmail represents the join table tracking which users received which emails
 type UserEmail struct {
 	ID        uint `gorm:"primaryKey"`
@@ -72,6 +106,42 @@ type UserEmail struct {
 	Email     EmailSent `gorm:"foreignKey:EmailID;constraint:OnDelete:CASCADE"`
 }
 
+// RunStat records the LLM usage and estimated cost of one pipeline stage of a run
+type RunStat struct {
+	ID           uint   `gorm:"primaryKey"`
+	EmailID      *uint  `gorm:"index"` // Nil if the run stopped before an email was recorded
+	Stage        string `gorm:"not null"`
+	Requests     int
+	PromptTokens int
+	OutputTokens int
+	CostUSD      float64
+	CreatedAt    time.Time  `gorm:"index"`
+	Email        *EmailSent `gorm:"foreignKey:EmailID;constraint:OnDelete:SET NULL"`
+}
+
+// LLMCacheEntry stores an LLM result for an article so unchanged articles aren't re-analyzed
+type LLMCacheEntry struct {
+	ID            uint      `gorm:"primaryKey"`
+	ArticleURL    string    `gorm:"not null;uniqueIndex:idx_llm_cache_key"`
+	ContentHash   string    `gorm:"not null;uniqueIndex:idx_llm_cache_key"`
+	Task          string    `gorm:"not null;uniqueIndex:idx_llm_cache_key"` // score, tags or summarize
+	PromptVersion string    `gorm:"not null;uniqueIndex:idx_llm_cache_key"`
+	Model         string    `gorm:"not null;uniqueIndex:idx_llm_cache_key"`
+	Response      string    `gorm:"type:text"`
+	CreatedAt     time.Time `gorm:"index"`
+}
+
+// ScoreSample is the raw relevance score an article got from one model and version of
+// the scoring prompts, kept as the reference distribution for score calibration
+type ScoreSample struct {
+	ID            uint      `gorm:"primaryKey"`
+	ArticleURL    string    `gorm:"not null;uniqueIndex:idx_score_sample_key"`
+	Model         string    `gorm:"not null;uniqueIndex:idx_score_sample_key;index:idx_score_sample_lookup"`
+	PromptVersion string    `gorm:"not null;uniqueIndex:idx_score_sample_key;index:idx_score_sample_lookup"`
+	Score         float64   `gorm:"type:decimal(3,1)"`
+	CreatedAt     time.Time `gorm:"index"`
+}
+
 // TableName overrides for GORM
 func (User) TableName() string {
 	return "users"
@@ -89,6 +159,18 @@ func (EmailArticle) TableName() string {
 	return "email_articles"
 }
 
+func (EmailArticleLink) TableName() string {
+	return "email_article_links"
+}
+
 func (UserEmail) TableName() string {
 	return "user_emails"
 }
+
+func (LLMCacheEntry) TableName() string {
+	return "llm_cache"
+}
+
+func (ScoreSample) TableName() string {
+	return "score_history"
+}
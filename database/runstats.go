@@ -0,0 +1,44 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+// CreateRunStats records a run's LLM usage by stage. emailID may be nil when the run
+// stopped before an email was recorded, so the spend still counts against the budget.
+func CreateRunStats(emailID *uint, stages []models.StageUsage) error {
+	if len(stages) == 0 {
+		return nil
+	}
+
+	stats := make([]RunStat, len(stages))
+	for i, stage := range stages {
+		stats[i] = RunStat{
+			EmailID:      emailID,
+			Stage:        stage.Stage,
+			Requests:     stage.Requests,
+			PromptTokens: stage.PromptTokens,
+			OutputTokens: stage.OutputTokens,
+			CostUSD:      stage.CostUSD,
+		}
+	}
+
+	result := DB.Create(&stats)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create run stats: %w", result.Error)
+	}
+	return nil
+}
+
+// GetLLMSpendSince returns the estimated LLM cost of all runs since the given time
+func GetLLMSpendSince(since time.Time) (float64, error) {
+	var total float64
+	result := DB.Model(&RunStat{}).Where("created_at >= ?", since).Select("COALESCE(SUM(cost_usd), 0)").Scan(&total)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to get LLM spend: %w", result.Error)
+	}
+	return total, nil
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// ScoreHistory is a database-backed history of raw relevance scores over a rolling window
+type ScoreHistory struct {
+	window time.Duration
+}
+
+// NewScoreHistory creates a score history that keeps scores saved within window
+func NewScoreHistory(window time.Duration) *ScoreHistory {
+	return &ScoreHistory{window: window}
+}
+
+// Scores returns the raw scores saved within the window for a model and scoring prompt
+// version, by article URL
+func (h *ScoreHistory) Scores(model, promptVersion string) (map[string]float64, error) {
+	var samples []ScoreSample
+	result := DB.Where("model = ? AND prompt_version = ? AND created_at > ?", model, promptVersion, time.Now().Add(-h.window)).Find(&samples)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get score history: %w", result.Error)
+	}
+
+	scores := make(map[string]float64, len(samples))
+	for _, sample := range samples {
+		scores[sample.ArticleURL] = sample.Score
+	}
+	return scores, nil
+}
+
+// Add saves a run's raw scores by article URL, refreshing articles scored before, and
+// deletes samples that have left the window
+func (h *ScoreHistory) Add(model, promptVersion string, scores map[string]float64) error {
+	if len(scores) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	samples := make([]ScoreSample, 0, len(scores))
+	for url, score := range scores {
+		samples = append(samples, ScoreSample{
+			ArticleURL:    url,
+			Model:         model,
+			PromptVersion: promptVersion,
+			Score:         score,
+			CreatedAt:     now,
+		})
+	}
+
+	result := DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "article_url"}, {Name: "model"}, {Name: "prompt_version"}},
+		DoUpdates: clause.AssignmentColumns([]string{"score", "created_at"}),
+	}).CreateInBatches(&samples, 500)
+	if result.Error != nil {
+		return fmt.Errorf("failed to save score history: %w", result.Error)
+	}
+
+	result = DB.Where("created_at <= ?", now.Add(-h.window)).Delete(&ScoreSample{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to prune score history: %w", result.Error)
+	}
+	return nil
+}
@@ -2,8 +2,20 @@ package database
 
 import (
 	"fmt"
+	"time"
 )
 
+// latencySmoothing is the weight of the newest fetch in a source's average latency
+const latencySmoothing = 0.2
+
+// SourceFetch is the outcome of one fetch of a source's feed
+type SourceFetch struct {
+	Err         error
+	Items       int
+	NotModified bool
+	Latency     time.Duration
+}
+
 // CreateSource creates a new RSS feed source in the database
 func CreateSource(name, category, url string, active bool) (*Source, error) {
 	source := &Source{
@@ -61,15 +73,90 @@ func GetSourceByURL(url string) (*Source, error) {
 	return &source, nil
 }
 
-// UpdateSourceActive updates a source's active status
+// UpdateSourceActive updates a source's active status. Reactivating a source clears its
+// failure streak and quarantine.
 func UpdateSourceActive(sourceID uint, active bool) error {
-	result := DB.Model(&Source{}).Where("id = ?", sourceID).Update("active", active)
+	updates := map[string]interface{}{"active": active}
+	if active {
+		updates["consecutive_failures"] = 0
+		updates["quarantined_at"] = nil
+	}
+	result := DB.Model(&Source{}).Where("id = ?", sourceID).Updates(updates)
 	if result.Error != nil {
 		return fmt.Errorf("failed to update source active status: %w", result.Error)
 	}
 	return nil
 }
 
+// UpdateSourceDetails updates a source's name and category
+func UpdateSourceDetails(sourceID uint, name, category string) error {
+	result := DB.Model(&Source{}).Where("id = ?", sourceID).Updates(map[string]interface{}{
+		"name":     name,
+		"category": category,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update source: %w", result.Error)
+	}
+	return nil
+}
+
+// UpdateSourceValidators saves the ETag and Last-Modified values a source's feed was
+// last fetched with
+func UpdateSourceValidators(url, etag, lastModified string) error {
+	result := DB.Model(&Source{}).Where("url = ?", url).Updates(map[string]interface{}{
+		"etag":          etag,
+		"last_modified": lastModified,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update source validators: %w", result.Error)
+	}
+	return nil
+}
+
+// RecordSourceFetch updates a source's fetch health. A source that has failed
+// maxFailures times in a row is deactivated (0 never deactivates); the result reports
+// whether this fetch deactivated it. Only the health columns are written, so the
+// source's validators and details are left as they are.
+func RecordSourceFetch(url string, fetch SourceFetch, maxFailures int) (bool, error) {
+	source, err := GetSourceByURL(url)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	updates := make(map[string]interface{})
+	failures := 0
+	if fetch.Err != nil {
+		failures = source.ConsecutiveFailures + 1
+		updates["last_error"] = fetch.Err.Error()
+		updates["last_error_at"] = now
+	} else {
+		updates["last_success_at"] = now
+		if !fetch.NotModified {
+			updates["last_item_count"] = fetch.Items
+		}
+		latency := float64(fetch.Latency.Milliseconds())
+		if source.AvgLatencyMs != 0 {
+			latency = source.AvgLatencyMs + latencySmoothing*(latency-source.AvgLatencyMs)
+		}
+		updates["avg_latency_ms"] = latency
+	}
+	updates["consecutive_failures"] = failures
+
+	quarantined := false
+	if maxFailures > 0 && failures >= maxFailures && source.Active {
+		updates["active"] = false
+		updates["quarantined_at"] = now
+		quarantined = true
+	}
+
+	result := DB.Model(&Source{}).Where("url = ?", url).Updates(updates)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update source health: %w", result.Error)
+	}
+	return quarantined, nil
+}
+
 // DeleteSource soft deletes a source
 func DeleteSource(sourceID uint) error {
 	result := DB.Delete(&Source{}, sourceID)
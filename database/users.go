@@ -3,7 +3,10 @@ package database
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+
+	"github.com/ty-e-boyd/thepaper/models"
 )
 
 // CreateUser creates a new user in the database
@@ -67,6 +70,63 @@ func UpdateUserSubscription(userID uint, subscribed bool) error {
 	return nil
 }
 
+// UpdateUserProfile updates a user's interests and category preferences
+func UpdateUserProfile(userID uint, profile models.UserProfile) error {
+	preferredJSON, err := json.Marshal(profile.PreferredCategories)
+	if err != nil {
+		return fmt.Errorf("failed to encode preferred categories: %w", err)
+	}
+	blockedJSON, err := json.Marshal(profile.BlockedCategories)
+	if err != nil {
+		return fmt.Errorf("failed to encode blocked categories: %w", err)
+	}
+
+	result := DB.Model(&User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"interests":            profile.Interests,
+		"preferred_categories": string(preferredJSON),
+		"blocked_categories":   string(blockedJSON),
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update user profile: %w", result.Error)
+	}
+	return nil
+}
+
+// UpdateUserSummaryStyle sets a user's preferred summary style; an empty style uses the default
+func UpdateUserSummaryStyle(userID uint, style string) error {
+	result := DB.Model(&User{}).Where("id = ?", userID).Update("summary_style", style)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update user summary style: %w", result.Error)
+	}
+	return nil
+}
+
+// UpdateUserFrequency sets whether a user receives the daily issue or the weekly roundup
+func UpdateUserFrequency(userID uint, frequency string) error {
+	result := DB.Model(&User{}).Where("id = ?", userID).Update("frequency", frequency)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update user frequency: %w", result.Error)
+	}
+	return nil
+}
+
+// IsWeekly reports whether the user receives the weekly roundup instead of daily issues
+func (u User) IsWeekly() bool {
+	return u.Frequency == models.FrequencyWeekly
+}
+
+// Profile returns the user's interest profile for personalized selection
+func (u User) Profile() models.UserProfile {
+	profile := models.UserProfile{Interests: u.Interests}
+	if u.PreferredCategories != "" {
+		json.Unmarshal([]byte(u.PreferredCategories), &profile.PreferredCategories)
+	}
+	if u.BlockedCategories != "" {
+		json.Unmarshal([]byte(u.BlockedCategories), &profile.BlockedCategories)
+	}
+	return profile
+}
+
 // generateUnsubscribeToken generates a random token for unsubscribe links
 func generateUnsubscribeToken() (string, error) {
 	bytes := make([]byte, 32)
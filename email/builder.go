@@ -2,6 +2,7 @@ package email
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -52,13 +53,88 @@ func getTagColor(tag string) string {
 
 // BuildHTML generates an HTML email from analyzed articles
 func BuildHTML(articles []models.AnalyzedArticle, totalArticles, totalSources int) string {
-	return BuildHTMLWithToken(articles, totalArticles, totalSources, "")
+	return BuildHTMLWithToken(articles, "", totalArticles, totalSources, "", models.SummaryOneLiner, 0)
 }
 
-// BuildHTMLWithToken generates an HTML email from analyzed articles with unsubscribe token
-func BuildHTMLWithToken(articles []models.AnalyzedArticle, totalArticles, totalSources int, unsubscribeToken string) string {
+// OpenTracking reports whether emails carry an open-tracking pixel, which is served by
+// "thepaper serve" at TRACKING_URL
+func OpenTracking() bool {
+	return os.Getenv("TRACKING_URL") != ""
+}
+
+// BuildHTMLWithToken generates an HTML email from analyzed articles with unsubscribe token,
+// rendering each summary in the given style. A non-empty intro is shown above the articles.
+// With open tracking on, the email carries a pixel reporting opens of emailID.
+func BuildHTMLWithToken(articles []models.AnalyzedArticle, intro string, totalArticles, totalSources int, unsubscribeToken, summaryStyle string, emailID uint) string {
 	var sb strings.Builder
+	writeHeader(&sb, "📰 The Paper", time.Now().Format("Monday, January 2, 2006"))
+
+	// Editorial intro
+	if intro != "" {
+		sb.WriteString(fmt.Sprintf(`
+		<div class="intro">
+			<h2>Today in tech</h2>
+			<p>%s</p>
+		</div>
+`, escapeHTML(intro)))
+	}
+
+	// Add articles
+	for i, article := range articles {
+		writeArticle(&sb, i+1, article, summaryStyle)
+	}
+
+	// Stats section
+	sb.WriteString(fmt.Sprintf(`
+		<div class="stats">
+			<p>📊 <strong>Today's Digest Stats:</strong> Analyzed <strong>%d articles</strong> from <strong>%d sources</strong></p>
+		</div>
+`, totalArticles, totalSources))
+
+	writeFooter(&sb, "daily digest", unsubscribeToken, emailID)
+	return sb.String()
+}
+
+// BuildWeeklyHTMLWithToken generates the weekly roundup email. Articles are grouped under
+// category headings, with categories ordered by their first article. With open tracking
+// on, the email carries a pixel reporting opens of emailID.
+func BuildWeeklyHTMLWithToken(articles []models.AnalyzedArticle, weekStart, weekEnd time.Time, totalArticles, totalIssues int, unsubscribeToken, summaryStyle string, emailID uint) string {
+	var sb strings.Builder
+	writeHeader(&sb, "📰 The Paper Weekly", fmt.Sprintf("Week of %s – %s", weekStart.Format("January 2"), weekEnd.Format("January 2, 2006")))
+
+	var categories []string
+	byCategory := make(map[string][]models.AnalyzedArticle)
+	for _, article := range articles {
+		if _, ok := byCategory[article.Category]; !ok {
+			categories = append(categories, article.Category)
+		}
+		byCategory[article.Category] = append(byCategory[article.Category], article)
+	}
+
+	number := 1
+	for _, category := range categories {
+		sb.WriteString(fmt.Sprintf(`
+		<h2 class="category-heading">%s</h2>
+`, escapeHTML(category)))
+		for _, article := range byCategory[category] {
+			writeArticle(&sb, number, article, summaryStyle)
+			number++
+		}
+	}
 
+	// Stats section
+	sb.WriteString(fmt.Sprintf(`
+		<div class="stats">
+			<p>📊 <strong>This Week's Roundup:</strong> The best of <strong>%d articles</strong> from <strong>%d daily issues</strong></p>
+		</div>
+`, totalArticles, totalIssues))
+
+	writeFooter(&sb, "weekly roundup", unsubscribeToken, emailID)
+	return sb.String()
+}
+
+// writeHeader writes the document head, styles and page heading
+func writeHeader(sb *strings.Builder, title, date string) {
 	// Email header and styles
 	sb.WriteString(`<!DOCTYPE html>
 <html>
@@ -93,6 +169,29 @@ func BuildHTMLWithToken(articles []models.AnalyzedArticle, totalArticles, totalS
 			font-size: 14px;
 			margin-bottom: 30px;
 		}
+		.intro {
+			margin-bottom: 30px;
+			padding: 15px 20px;
+			background-color: #f8f9fa;
+			border-left: 4px solid #3498db;
+			border-radius: 4px;
+		}
+		.intro h2 {
+			font-size: 16px;
+			color: #2c3e50;
+			margin: 0 0 8px 0;
+		}
+		.intro p {
+			margin: 0;
+			color: #555;
+		}
+		.category-heading {
+			font-size: 18px;
+			color: #2c3e50;
+			margin: 30px 0 15px 0;
+			padding-bottom: 6px;
+			border-bottom: 2px solid #ecf0f1;
+		}
 		.article {
 			margin-bottom: 30px;
 			padding-bottom: 20px;
@@ -147,6 +246,27 @@ func BuildHTMLWithToken(articles []models.AnalyzedArticle, totalArticles, totalS
 			line-height: 1.7;
 			margin-bottom: 10px;
 		}
+		.article-bullets {
+			color: #555;
+			line-height: 1.7;
+			margin: 0 0 10px 0;
+			padding-left: 20px;
+		}
+		.why-it-matters {
+			color: #555;
+			line-height: 1.7;
+			margin-bottom: 10px;
+			padding-left: 12px;
+			border-left: 3px solid #3498db;
+		}
+		.also-on {
+			font-size: 13px;
+			color: #7f8c8d;
+			margin-bottom: 10px;
+		}
+		.also-on a {
+			color: #7f8c8d;
+		}
 		.read-more {
 			display: inline-block;
 			color: #3498db;
@@ -181,23 +301,24 @@ func BuildHTMLWithToken(articles []models.AnalyzedArticle, totalArticles, totalS
 </head>
 <body>
 	<div class="container">
-		<h1>📰 The Paper</h1>
-		<div class="date">` + time.Now().Format("Monday, January 2, 2006") + `</div>
+		<h1>` + title + `</h1>
+		<div class="date">` + date + `</div>
 `)
+}
 
-	// Add articles
-	for i, article := range articles {
-		// Build tags HTML
-		tagsHTML := ""
-		if len(article.Tags) > 0 {
-			for _, tag := range article.Tags {
-				capitalizedTag := capitalizeTag(tag)
-				color := getTagColor(tag)
-				tagsHTML += fmt.Sprintf(`<span class="tag" style="background-color: %s;">%s</span>`, color, escapeHTML(capitalizedTag))
-			}
+// writeArticle writes one numbered article with its tags, summary and related coverage
+func writeArticle(sb *strings.Builder, number int, article models.AnalyzedArticle, summaryStyle string) {
+	// Build tags HTML
+	tagsHTML := ""
+	if len(article.Tags) > 0 {
+		for _, tag := range article.Tags {
+			capitalizedTag := capitalizeTag(tag)
+			color := getTagColor(tag)
+			tagsHTML += fmt.Sprintf(`<span class="tag" style="background-color: %s;">%s</span>`, color, escapeHTML(capitalizedTag))
 		}
+	}
 
-		sb.WriteString(fmt.Sprintf(`
+	sb.WriteString(fmt.Sprintf(`
 		<div class="article">
 			<div class="article-title">
 				<a href="%s" target="_blank">%d. %s</a>
@@ -209,24 +330,18 @@ func BuildHTMLWithToken(articles []models.AnalyzedArticle, totalArticles, totalS
 			<div class="article-tags">
 				%s
 			</div>
-			<div class="article-summary">
-				%s
-			</div>
+			%s
+			%s
 			<a href="%s" class="read-more" target="_blank">Read full article →</a>
 		</div>
-`, article.Link, i+1, escapeHTML(article.Title), escapeHTML(article.Category),
-			escapeHTML(article.Source), article.RelevanceScore, tagsHTML,
-			escapeHTML(article.Summary), article.Link))
-	}
-
-	// Stats section
-	sb.WriteString(fmt.Sprintf(`
-		<div class="stats">
-			<p>📊 <strong>Today's Digest Stats:</strong> Analyzed <strong>%d articles</strong> from <strong>%d sources</strong></p>
-		</div>
-`, totalArticles, totalSources))
+`, article.Link, number, escapeHTML(article.Title), escapeHTML(article.Category),
+		escapeHTML(article.Source), article.RelevanceScore, tagsHTML,
+		summaryHTML(article, summaryStyle), alsoOnHTML(article), article.Link))
+}
 
-	// Footer with unsubscribe link
+// writeFooter writes the footer with the unsubscribe link and tracking pixel and closes
+// the document
+func writeFooter(sb *strings.Builder, digest, unsubscribeToken string, emailID uint) {
 	portfolioURL := os.Getenv("PORTFOLIO_URL")
 	if portfolioURL == "" {
 		portfolioURL = "http://localhost:4040"
@@ -237,17 +352,65 @@ func BuildHTMLWithToken(articles []models.AnalyzedArticle, totalArticles, totalS
 		unsubscribeLink = fmt.Sprintf(`<p><a href="%s/unsubscribe?token=%s" style="color: #95a5a6;">Unsubscribe from this newsletter</a></p>`, portfolioURL, unsubscribeToken)
 	}
 
+	// The subscriber's token identifies who opened the email
+	trackingPixel := ""
+	if OpenTracking() && unsubscribeToken != "" && emailID != 0 {
+		trackingPixel = fmt.Sprintf(`<img src="%s/open?email=%d&amp;token=%s" width="1" height="1" alt="" style="display: block; border: 0;">`,
+			strings.TrimRight(os.Getenv("TRACKING_URL"), "/"), emailID, url.QueryEscape(unsubscribeToken))
+	}
+
 	sb.WriteString(fmt.Sprintf(`
 		<div class="footer">
-			<p>You're receiving this because you subscribed to The Paper daily digest.</p>
-			<p>Curated and summarized by AI | Powered by Gemini</p>
+			<p>You're receiving this because you subscribed to The Paper %s.</p>
+			<p>Curated and summarized by AI</p>
 			%s
 		</div>
+		%s
 	</div>
 </body>
-</html>`, unsubscribeLink))
+</html>`, digest, unsubscribeLink, trackingPixel))
+}
 
-	return sb.String()
+// summaryHTML renders an article's summary in the given style, falling back to the
+// one-liner when that style was not generated for the article
+func summaryHTML(article models.AnalyzedArticle, style string) string {
+	oneLiner := fmt.Sprintf(`<div class="article-summary">
+				%s
+			</div>`, escapeHTML(article.Summary))
+
+	switch {
+	case style == models.SummaryBullets && len(article.Bullets) > 0:
+		var sb strings.Builder
+		sb.WriteString(`<ul class="article-bullets">`)
+		for _, bullet := range article.Bullets {
+			sb.WriteString(fmt.Sprintf(`
+				<li>%s</li>`, escapeHTML(bullet)))
+		}
+		sb.WriteString(`
+			</ul>`)
+		return sb.String()
+	case style == models.SummaryWhyItMatters && article.WhyItMatters != "":
+		return oneLiner + fmt.Sprintf(`
+			<div class="why-it-matters">
+				<strong>Why it matters:</strong> %s
+			</div>`, escapeHTML(article.WhyItMatters))
+	default:
+		return oneLiner
+	}
+}
+
+// alsoOnHTML renders the "Also on" line linking other sources that covered the story
+func alsoOnHTML(article models.AnalyzedArticle) string {
+	others := article.OtherSources()
+	if len(others) == 0 {
+		return ""
+	}
+
+	links := make([]string, len(others))
+	for i, related := range others {
+		links[i] = fmt.Sprintf(`<a href="%s" target="_blank">%s</a>`, escapeHTML(related.Link), escapeHTML(related.Source))
+	}
+	return fmt.Sprintf(`<div class="also-on">Also on: %s</div>`, strings.Join(links, ", "))
 }
 
 // escapeHTML escapes special HTML characters
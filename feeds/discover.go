@@ -0,0 +1,179 @@
+package feeds
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// feedLinkTypes are the <link rel="alternate"> types that point to a feed
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+	"application/json":      true,
+	"application/xml":       true,
+	"text/xml":              true,
+}
+
+// commonFeedPaths are tried on every site, since pages often link only some of their feeds
+var commonFeedPaths = []string{"/feed", "/rss.xml", "/feed.xml", "/atom.xml", "/rss", "/index.xml", "/feed.json"}
+
+// DiscoveredFeed is a feed found for a website, validated by downloading and parsing it
+type DiscoveredFeed struct {
+	URL   string
+	Title string // The feed's own title
+	Items int
+	Name  string // Suggested source name: the feed title, else the page title, else the host
+}
+
+// Discover finds the feeds of a website. The page's <link rel="alternate"> feed entries
+// are tried first, then the common feed paths; each candidate is kept only if it parses
+// as RSS, Atom or JSON Feed. A URL that is itself a feed is returned as is.
+func (f *Fetcher) Discover(ctx context.Context, siteURL string) ([]DiscoveredFeed, error) {
+	if !strings.Contains(siteURL, "://") {
+		siteURL = "https://" + siteURL
+	}
+
+	body, pageURL, err := f.download(ctx, siteURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", siteURL, err)
+	}
+	if parsed, err := f.parser.Parse(bytes.NewReader(body)); err == nil {
+		return []DiscoveredFeed{{
+			URL:   pageURL.String(),
+			Title: strings.TrimSpace(parsed.Title),
+			Items: len(parsed.Items),
+			Name:  suggestSourceName(parsed.Title, "", pageURL),
+		}}, nil
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse page: %w", err)
+	}
+	pageTitle := strings.TrimSpace(doc.Find("title").First().Text())
+
+	var candidates []string
+	seen := make(map[string]bool)
+	addCandidate := func(ref string) string {
+		resolved, err := pageURL.Parse(strings.TrimSpace(ref))
+		if err != nil || seen[resolved.String()] {
+			return ""
+		}
+		seen[resolved.String()] = true
+		candidates = append(candidates, resolved.String())
+		return resolved.String()
+	}
+	// Failures are only worth reporting for feeds the page links; most common paths miss
+	linked := make(map[string]bool)
+	doc.Find("link[rel][href]").Each(func(_ int, s *goquery.Selection) {
+		rel, _ := s.Attr("rel")
+		linkType, _ := s.Attr("type")
+		href, _ := s.Attr("href")
+		mediaType, _, _ := strings.Cut(strings.ToLower(linkType), ";")
+		if containsField(rel, "alternate") && feedLinkTypes[strings.TrimSpace(mediaType)] {
+			if candidate := addCandidate(href); candidate != "" {
+				linked[candidate] = true
+			}
+		}
+	})
+	for _, path := range commonFeedPaths {
+		addCandidate(path)
+	}
+
+	var found []DiscoveredFeed
+	for _, candidate := range candidates {
+		feedBody, feedURL, err := f.download(ctx, candidate)
+		if err != nil {
+			if linked[candidate] {
+				log.Printf("  ✗ Linked feed %s failed: %v", candidate, err)
+			}
+			continue
+		}
+		parsed, err := f.parser.Parse(bytes.NewReader(feedBody))
+		if err != nil {
+			if linked[candidate] {
+				log.Printf("  ✗ Linked feed %s is not a valid feed: %v", candidate, err)
+			}
+			continue
+		}
+		// Several candidates can redirect to the same feed
+		if feedURL.String() != candidate && seen[feedURL.String()] {
+			continue
+		}
+		seen[feedURL.String()] = true
+		found = append(found, DiscoveredFeed{
+			URL:   feedURL.String(),
+			Title: strings.TrimSpace(parsed.Title),
+			Items: len(parsed.Items),
+			Name:  suggestSourceName(parsed.Title, pageTitle, pageURL),
+		})
+	}
+
+	if len(found) == 0 {
+		if len(linked) > 0 {
+			return nil, fmt.Errorf("none of the %d feeds linked from %s could be parsed, and none were found at the common feed paths", len(linked), siteURL)
+		}
+		return nil, fmt.Errorf("no feeds linked from %s or found at the common feed paths", siteURL)
+	}
+	return found, nil
+}
+
+// download fetches a URL with the fetcher's User-Agent and size limit, returning the
+// body and the final URL after redirects
+func (f *Fetcher) download(ctx context.Context, rawURL string) ([]byte, *url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBody+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if int64(len(body)) > f.maxBody {
+		return nil, nil, fmt.Errorf("response is larger than the %s limit", FormatBytes(f.maxBody))
+	}
+	return body, resp.Request.URL, nil
+}
+
+// suggestSourceName picks a source name from the feed title, the site's page title or
+// the site's host, in that order
+func suggestSourceName(feedTitle, pageTitle string, pageURL *url.URL) string {
+	if name := strings.TrimSpace(feedTitle); name != "" {
+		return name
+	}
+	if name := strings.TrimSpace(pageTitle); name != "" {
+		return name
+	}
+	return strings.TrimPrefix(pageURL.Hostname(), "www.")
+}
+
+// containsField reports whether a space-separated attribute value such as rel contains
+// field, ignoring case
+func containsField(value, field string) bool {
+	for _, v := range strings.Fields(value) {
+		if strings.EqualFold(v, field) {
+			return true
+		}
+	}
+	return false
+}
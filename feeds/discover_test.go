@@ -0,0 +1,231 @@
+package feeds
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+// testRSS returns an RSS document with the given channel title and one item
+func testRSS(title string) string {
+	return `<?xml version="1.0"?><rss version="2.0"><channel><title>` + title + `</title>
+		<item><title>First post</title><link>https://example.com/first</link></item>
+	</channel></rss>`
+}
+
+// testAtom returns an Atom document with the given title and two entries
+func testAtom(title string) string {
+	return `<?xml version="1.0"?><feed xmlns="http://www.w3.org/2005/Atom"><title>` + title + `</title>
+		<entry><title>One</title><link href="https://example.com/one"/></entry>
+		<entry><title>Two</title><link href="https://example.com/two"/></entry>
+	</feed>`
+}
+
+// testPage returns an HTML page with the given title and head elements
+func testPage(title, head string) string {
+	return `<!DOCTYPE html><html><head><title>` + title + `</title>` + head + `</head><body><p>Hello</p></body></html>`
+}
+
+func TestDiscover(t *testing.T) {
+	tests := []struct {
+		name    string
+		pages   map[string]string // Served bodies by path; other paths are 404
+		path    string            // Path passed to Discover
+		want    []DiscoveredFeed  // URLs are relative to the test server
+		wantErr bool
+	}{
+		{
+			name: "relative href resolved against the page",
+			pages: map[string]string{
+				"/blog/":         testPage("Dev Blog", `<link rel="alternate" type="application/rss+xml" href="feed.xml">`),
+				"/blog/feed.xml": testRSS("Dev Blog Posts"),
+			},
+			path: "/blog/",
+			want: []DiscoveredFeed{{URL: "/blog/feed.xml", Title: "Dev Blog Posts", Items: 1, Name: "Dev Blog Posts"}},
+		},
+		{
+			name: "root-relative href with type parameters",
+			pages: map[string]string{
+				"/blog/post": testPage("A post", `<link rel="Alternate" type="application/atom+xml; charset=utf-8" href="/atom.xml">`),
+				"/atom.xml":  testAtom("Atom Posts"),
+			},
+			path: "/blog/post",
+			want: []DiscoveredFeed{{URL: "/atom.xml", Title: "Atom Posts", Items: 2, Name: "Atom Posts"}},
+		},
+		{
+			name: "non-feed links ignored and duplicate links listed once",
+			pages: map[string]string{
+				"/": testPage("Home", `<link rel="stylesheet" type="text/css" href="/styles.css">
+					<link rel="alternate" type="text/html" hreflang="de" href="/de/">
+					<link rel="alternate" type="application/rss+xml" href="/rss.xml">
+					<link rel="alternate" type="application/rss+xml" href="rss.xml">`),
+				"/rss.xml": testRSS("Home Feed"),
+			},
+			path: "/",
+			want: []DiscoveredFeed{{URL: "/rss.xml", Title: "Home Feed", Items: 1, Name: "Home Feed"}},
+		},
+		{
+			name: "broken linked feed skipped",
+			pages: map[string]string{
+				"/": testPage("Home", `<link rel="alternate" type="application/rss+xml" href="/missing.xml">
+					<link rel="alternate" type="application/rss+xml" href="/not-a-feed.xml">
+					<link rel="alternate" type="application/atom+xml" href="/atom.xml">`),
+				"/not-a-feed.xml": "<html>nope</html>",
+				"/atom.xml":       testAtom("Atom Posts"),
+			},
+			path: "/",
+			want: []DiscoveredFeed{{URL: "/atom.xml", Title: "Atom Posts", Items: 2, Name: "Atom Posts"}},
+		},
+		{
+			name: "untitled feed named after the page",
+			pages: map[string]string{
+				"/":         testPage("  Example News  ", `<link rel="alternate" type="application/rss+xml" href="/feed.xml">`),
+				"/feed.xml": testRSS(""),
+			},
+			path: "/",
+			want: []DiscoveredFeed{{URL: "/feed.xml", Items: 1, Name: "Example News"}},
+		},
+		{
+			name: "common paths tried without links",
+			pages: map[string]string{
+				"/":        testPage("Home", ""),
+				"/rss.xml": testRSS("Fallback Feed"),
+			},
+			path: "/",
+			want: []DiscoveredFeed{{URL: "/rss.xml", Title: "Fallback Feed", Items: 1, Name: "Fallback Feed"}},
+		},
+		{
+			name: "feed URL returned as is",
+			pages: map[string]string{
+				"/feed.xml": testRSS("Direct Feed"),
+			},
+			path: "/feed.xml",
+			want: []DiscoveredFeed{{URL: "/feed.xml", Title: "Direct Feed", Items: 1, Name: "Direct Feed"}},
+		},
+		{
+			name: "no feeds",
+			pages: map[string]string{
+				"/": testPage("Home", ""),
+			},
+			path:    "/",
+			wantErr: true,
+		},
+		{
+			name: "feed at a common path found alongside the linked one",
+			pages: map[string]string{
+				"/":               testPage("Home", `<link rel="alternate" type="application/atom+xml" href="/atom/posts.xml">`),
+				"/atom/posts.xml": testAtom("Posts"),
+				"/feed":           testRSS("Everything"),
+			},
+			path: "/",
+			want: []DiscoveredFeed{
+				{URL: "/atom/posts.xml", Title: "Posts", Items: 2, Name: "Posts"},
+				{URL: "/feed", Title: "Everything", Items: 1, Name: "Everything"},
+			},
+		},
+		{
+			name: "linked feed found again at a common path listed once",
+			pages: map[string]string{
+				"/":         testPage("Home", `<link rel="alternate" type="application/rss+xml" href="/feed.xml">`),
+				"/feed.xml": testRSS("Home Feed"),
+			},
+			path: "/",
+			want: []DiscoveredFeed{{URL: "/feed.xml", Title: "Home Feed", Items: 1, Name: "Home Feed"}},
+		},
+		{
+			name: "broken linked feed falls back to a common path",
+			pages: map[string]string{
+				"/":        testPage("Home", `<link rel="alternate" type="application/rss+xml" href="/old-feed.xml">`),
+				"/rss.xml": testRSS("Current Feed"),
+			},
+			path: "/",
+			want: []DiscoveredFeed{{URL: "/rss.xml", Title: "Current Feed", Items: 1, Name: "Current Feed"}},
+		},
+		{
+			name: "no linked feed parses",
+			pages: map[string]string{
+				"/":          testPage("Home", `<link rel="alternate" type="application/rss+xml" href="/posts.xml">`),
+				"/posts.xml": "<html>nope</html>",
+			},
+			path:    "/",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, ok := tt.pages[r.URL.Path]
+				if !ok {
+					http.NotFound(w, r)
+					return
+				}
+				w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			fetcher := NewFetcher(models.FetchConfig{Timeout: 5 * time.Second, MaxBodyBytes: 1 << 20})
+			got, err := fetcher.Discover(context.Background(), server.URL+tt.path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Discover returned %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Discover: %v", err)
+			}
+
+			want := make([]DiscoveredFeed, len(tt.want))
+			for i, feed := range tt.want {
+				feed.URL = server.URL + feed.URL
+				want[i] = feed
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Discover = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestSuggestSourceName(t *testing.T) {
+	pageURL, _ := url.Parse("https://www.example.com/blog/")
+	tests := []struct {
+		feedTitle, pageTitle, want string
+	}{
+		{feedTitle: " Feed Title ", pageTitle: "Page Title", want: "Feed Title"},
+		{feedTitle: "  ", pageTitle: " Page Title ", want: "Page Title"},
+		{feedTitle: "", pageTitle: "", want: "example.com"},
+	}
+
+	for _, tt := range tests {
+		if got := suggestSourceName(tt.feedTitle, tt.pageTitle, pageURL); got != tt.want {
+			t.Errorf("suggestSourceName(%q, %q) = %q, want %q", tt.feedTitle, tt.pageTitle, got, tt.want)
+		}
+	}
+}
+
+func TestContainsField(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{value: "alternate", want: true},
+		{value: "Alternate home", want: true},
+		{value: "  home   ALTERNATE ", want: true},
+		{value: "alternates", want: false},
+		{value: "", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := containsField(tt.value, "alternate"); got != tt.want {
+			t.Errorf("containsField(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
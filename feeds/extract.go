@@ -0,0 +1,256 @@
+package feeds
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ty-e-boyd/thepaper/models"
+	"golang.org/x/net/html"
+)
+
+const (
+	maxPageBytes      = 5 << 20 // Pages are read up to 5 MB
+	minParagraphChars = 25      // Shorter paragraphs do not count towards a candidate's score
+	minExtractedChars = 200     // Less text than this is treated as a failed extraction
+	userAgent         = "Mozilla/5.0 (compatible; ThePaper/1.0; +https://github.com/ty-e-boyd/thepaper)"
+)
+
+// Patterns over an element's class and id, adapted from Mozilla's Readability
+var (
+	unlikelyNames = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|cookie|disqus|footer|header|menu|modal|nav|newsletter|popup|promo|related|remark|share|sidebar|social|sponsor|subscribe|advert`)
+	maybeNames    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	positiveNames = regexp.MustCompile(`(?i)article|blog|body|content|entry|main|page|post|story|text`)
+	negativeNames = regexp.MustCompile(`(?i)comment|footer|footnote|masthead|meta|nav|related|share|sidebar|social|sponsor|widget|advert|promo`)
+)
+
+// Extractor fetches article pages and extracts their main readable text
+type Extractor struct {
+	client    *http.Client
+	maxChars  int
+	userAgent string
+	hosts     *hostLimiter
+}
+
+// NewExtractor creates an extractor that caps extracted text at maxChars characters
+// (0 means no cap). Pages are requested with the fetcher's timeout and User-Agent, and
+// share its per-host limits, so feeds and article pages on one host are throttled together.
+func (f *Fetcher) NewExtractor(maxChars int) *Extractor {
+	return &Extractor{
+		client:    f.client,
+		maxChars:  maxChars,
+		userAgent: f.userAgent,
+		hosts:     f.hosts,
+	}
+}
+
+// Extract fetches the article's page and returns its main text, or an error if the page
+// cannot be fetched or has too little readable text
+func (e *Extractor) Extract(ctx context.Context, article models.Article) (string, error) {
+	if article.Link == "" {
+		return "", fmt.Errorf("article has no link")
+	}
+
+	release, err := e.hosts.acquire(ctx, feedHost(article.Link))
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, article.Link, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", e.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" && !strings.Contains(contentType, "html") {
+		return "", fmt.Errorf("not an HTML page (%s)", contentType)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(io.LimitReader(resp.Body, maxPageBytes))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse page: %w", err)
+	}
+
+	text := extractReadableText(doc)
+	if length := len([]rune(text)); length < minExtractedChars {
+		return "", fmt.Errorf("no readable content found (%d characters)", length)
+	}
+	return capText(text, e.maxChars), nil
+}
+
+// extractReadableText finds the element holding the article body and returns its
+// paragraphs. Paragraph-bearing elements are scored by the text they contain, weighted
+// by class and id names; the best one, discounted by link density, wins.
+func extractReadableText(doc *goquery.Document) string {
+	doc.Find("script, style, noscript, iframe, svg, form, nav, header, footer, aside, button, select").Remove()
+	doc.Find("body *").Each(func(_ int, s *goquery.Selection) {
+		names := elementNames(s)
+		if names != "" && unlikelyNames.MatchString(names) && !maybeNames.MatchString(names) && !s.Is("article, main") {
+			s.Remove()
+		}
+	})
+
+	scores := make(map[*html.Node]float64)
+	addScore := func(s *goquery.Selection, score float64) {
+		if s.Length() == 0 || s.Is("html") {
+			return
+		}
+		node := s.Get(0)
+		if _, ok := scores[node]; !ok {
+			scores[node] = initialScore(s)
+		}
+		scores[node] += score
+	}
+
+	doc.Find("p, pre, td").Each(func(_ int, p *goquery.Selection) {
+		text := strings.TrimSpace(p.Text())
+		if len(text) < minParagraphChars {
+			return
+		}
+		score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+		parent := p.Parent()
+		addScore(parent, score)
+		addScore(parent.Parent(), score/2)
+	})
+
+	var top *html.Node
+	var topScore float64
+	for node, score := range scores {
+		score *= 1 - linkDensity(goquery.NewDocumentFromNode(node).Selection)
+		scores[node] = score
+		if top == nil || score > topScore {
+			top, topScore = node, score
+		}
+	}
+	if top == nil {
+		return normalizeSpace(doc.Find("body").Text())
+	}
+
+	// Siblings that score well (e.g. a lead paragraph outside the main container) are
+	// part of the article too
+	threshold := math.Max(10, topScore*0.2)
+	var blocks []string
+	topSelection := doc.FindNodes(top)
+	content := topSelection
+	if topSelection.Parent().Length() > 0 {
+		content = topSelection.Parent().Children().FilterFunction(func(_ int, s *goquery.Selection) bool {
+			return s.Get(0) == top || scores[s.Get(0)] >= threshold
+		})
+	}
+	content.Each(func(_ int, s *goquery.Selection) {
+		blocks = append(blocks, paragraphs(s)...)
+	})
+
+	if text := strings.Join(blocks, "\n\n"); len(text) >= minExtractedChars {
+		return text
+	}
+	return normalizeSpace(topSelection.Text())
+}
+
+// initialScore weights an element by its tag and its class and id names
+func initialScore(s *goquery.Selection) float64 {
+	var score float64
+	switch goquery.NodeName(s) {
+	case "article", "main":
+		score = 10
+	case "div":
+		score = 5
+	case "pre", "td", "blockquote":
+		score = 3
+	case "ol", "ul", "dl", "form":
+		score = -3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		score = -5
+	}
+
+	names := elementNames(s)
+	if positiveNames.MatchString(names) {
+		score += 25
+	}
+	if negativeNames.MatchString(names) {
+		score -= 25
+	}
+	return score
+}
+
+// paragraphs returns the text of the block elements inside s, skipping link lists
+func paragraphs(s *goquery.Selection) []string {
+	const blockSelector = "p, pre, h2, h3, h4, li, blockquote"
+
+	var blocks []string
+	if s.Is(blockSelector) {
+		if text := normalizeSpace(s.Text()); text != "" {
+			blocks = append(blocks, text)
+		}
+		return blocks
+	}
+
+	s.Find(blockSelector).Each(func(_ int, block *goquery.Selection) {
+		// Nested blocks (a paragraph inside a list item) are covered by their outer block
+		if block.ParentsUntilSelection(s).Filter(blockSelector).Length() > 0 {
+			return
+		}
+		text := normalizeSpace(block.Text())
+		if text == "" || linkDensity(block) > 0.5 {
+			return
+		}
+		blocks = append(blocks, text)
+	})
+	return blocks
+}
+
+// linkDensity returns the share of an element's text that is inside links
+func linkDensity(s *goquery.Selection) float64 {
+	textLength := len(strings.TrimSpace(s.Text()))
+	if textLength == 0 {
+		return 0
+	}
+
+	linkLength := 0
+	s.Find("a").Each(func(_ int, link *goquery.Selection) {
+		linkLength += len(strings.TrimSpace(link.Text()))
+	})
+	return math.Min(float64(linkLength)/float64(textLength), 1)
+}
+
+// elementNames returns an element's class and id attributes
+func elementNames(s *goquery.Selection) string {
+	class, _ := s.Attr("class")
+	id, _ := s.Attr("id")
+	return strings.TrimSpace(class + " " + id)
+}
+
+// normalizeSpace collapses runs of whitespace into single spaces
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// capText shortens text to at most maxChars characters, cutting at a word boundary
+func capText(text string, maxChars int) string {
+	runes := []rune(text)
+	if maxChars <= 0 || len(runes) <= maxChars {
+		return text
+	}
+
+	cut := string(runes[:maxChars-1])
+	if i := strings.LastIndexAny(cut, " \n"); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut) + "…"
+}
@@ -0,0 +1,180 @@
+package feeds
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+const articleParagraphs = `
+<p>The new release of the compiler reduces build times for large projects by caching intermediate results across packages, which the team measured on several open source codebases.</p>
+<p>Developers upgrading from the previous version do not need to change their code, although a handful of deprecated flags now print warnings, and two of them will be removed next year.</p>
+<p>The release notes also describe improvements to the linker, a smaller runtime footprint, and better error messages when generic type constraints are not satisfied.</p>`
+
+// testArticlePage is a typical blog post surrounded by site chrome
+const testArticlePage = `<!DOCTYPE html>
+<html><head><title>Compiler release</title><style>p { color: red; }</style></head>
+<body>
+<header><a href="/">Home</a> <a href="/blog">Blog</a> <a href="/about">About</a></header>
+<nav><ul><li><a href="/tags/go">Go</a></li><li><a href="/tags/rust">Rust</a></li></ul></nav>
+<div class="sidebar"><p>Subscribe to our newsletter for weekly updates from the team and friends.</p></div>
+<div class="post-content">
+<h2>Faster builds</h2>` + articleParagraphs + `
+</div>
+<div id="comments"><p>Great post, thanks for sharing these detailed benchmark numbers with us all!</p></div>
+<div class="related"><a href="/a">Another post about compilers and build systems</a></div>
+<footer><p>Copyright 2025 Example Blog. All rights reserved. Terms of service apply.</p></footer>
+<script>console.log("tracking");</script>
+</body></html>`
+
+func TestExtractReadableText(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     string
+		want     []string
+		unwanted []string
+	}{
+		{
+			name: "site chrome removed",
+			page: testArticlePage,
+			want: []string{
+				"Faster builds",
+				"reduces build times for large projects",
+				"handful of deprecated flags",
+				"improvements to the linker",
+			},
+			unwanted: []string{"Home", "Rust", "newsletter", "Great post", "Another post", "Copyright", "tracking", "color: red"},
+		},
+		{
+			name: "link list inside the article skipped",
+			page: `<html><body><article>` + articleParagraphs + `
+<ul><li><a href="/1">First related story on this blog</a></li><li><a href="/2">Second related story on this blog</a></li></ul>
+</article></body></html>`,
+			want:     []string{"reduces build times", "improvements to the linker"},
+			unwanted: []string{"related story"},
+		},
+		{
+			name: "paragraphs joined as blocks",
+			page: `<html><body><main>` + articleParagraphs + `</main></body></html>`,
+			want: []string{"codebases.\n\nDevelopers upgrading"},
+		},
+		{
+			name: "page without paragraphs",
+			page: `<html><body><div>Just a single line of   text on the page.</div></body></html>`,
+			want: []string{"Just a single line of text on the page."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.page))
+			if err != nil {
+				t.Fatalf("failed to parse page: %v", err)
+			}
+
+			text := extractReadableText(doc)
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("extracted text is missing %q:\n%s", want, text)
+				}
+			}
+			for _, unwanted := range tt.unwanted {
+				if strings.Contains(text, unwanted) {
+					t.Errorf("extracted text contains %q:\n%s", unwanted, text)
+				}
+			}
+		})
+	}
+}
+
+func TestCapText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		maxChars int
+		want     string
+	}{
+		{name: "no cap", text: "one two three", maxChars: 0, want: "one two three"},
+		{name: "within the cap", text: "one two three", maxChars: 13, want: "one two three"},
+		{name: "cut at a word boundary", text: "one two three four", maxChars: 12, want: "one two…"},
+		{name: "cut at a line break", text: "first line\nsecond line", maxChars: 15, want: "first line…"},
+		{name: "single long word cut hard", text: "abcdefghijklmnop", maxChars: 8, want: "abcdefg…"},
+		{name: "counted in characters", text: "ééé ééé ééé", maxChars: 8, want: "ééé ééé…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := capText(tt.text, tt.maxChars); got != tt.want {
+				t.Errorf("capText(%q, %d) = %q, want %q", tt.text, tt.maxChars, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	var agents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents = append(agents, r.Header.Get("User-Agent"))
+		switch r.URL.Path {
+		case "/post":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(testArticlePage))
+		case "/short":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><body><p>Too short to be an article.</p></body></html>`))
+		case "/pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("%PDF-1.7"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		maxChars int
+		wantErr  string // Substring of the expected error; empty for success
+	}{
+		{name: "article", path: "/post"},
+		{name: "article capped", path: "/post", maxChars: 250},
+		{name: "too little text", path: "/short", wantErr: "no readable content"},
+		{name: "not HTML", path: "/pdf", wantErr: "not an HTML page"},
+		{name: "missing page", path: "/gone", wantErr: "404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := NewFetcher(models.FetchConfig{Timeout: 5 * time.Second, UserAgent: "test-agent"})
+			text, err := fetcher.NewExtractor(tt.maxChars).Extract(context.Background(), models.Article{Link: server.URL + tt.path})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Extract error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Extract: %v", err)
+			}
+			if !strings.Contains(text, "reduces build times") {
+				t.Errorf("extracted text is missing the article body:\n%s", text)
+			}
+			if length := len([]rune(text)); tt.maxChars > 0 && length > tt.maxChars {
+				t.Errorf("extracted %d characters, over the cap of %d", length, tt.maxChars)
+			}
+		})
+	}
+
+	for _, agent := range agents {
+		if agent != "test-agent" {
+			t.Errorf("page requested as %q, want the fetcher's User-Agent", agent)
+		}
+	}
+}
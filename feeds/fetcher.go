@@ -1,49 +1,112 @@
 package feeds
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"sync"
+	"time"
 
 	"github.com/mmcdole/gofeed"
 	"github.com/ty-e-boyd/thepaper/models"
 )
 
+// Feed is a feed to fetch, with the cache validators saved from its last fetch
+type Feed struct {
+	URL          string
+	ETag         string
+	LastModified string
+}
+
+// FeedResult is the outcome of fetching one feed
+type FeedResult struct {
+	Feed        Feed // With the validators to send on the next fetch
+	Articles    int
+	NotModified bool          // The server answered 304, so the feed has no new items
+	Bytes       int64         // Response body bytes downloaded
+	Latency     time.Duration // Time to fetch and parse the feed
+	Err         error
+
+	validatorsChanged bool
+}
+
 // Fetcher handles fetching and parsing RSS feeds
 type Fetcher struct {
-	parser *gofeed.Parser
+	parser      *gofeed.Parser
+	client      *http.Client
+	userAgent   string
+	maxBody     int64
+	concurrency int
+	hosts       *hostLimiter
 }
 
 // NewFetcher creates a new RSS feed fetcher
-func NewFetcher() *Fetcher {
+func NewFetcher(cfg models.FetchConfig) *Fetcher {
+	agent := cfg.UserAgent
+	if agent == "" {
+		agent = userAgent
+	}
 	return &Fetcher{
-		parser: gofeed.NewParser(),
+		parser:      gofeed.NewParser(),
+		client:      &http.Client{Timeout: cfg.Timeout},
+		userAgent:   agent,
+		maxBody:     cfg.MaxBodyBytes,
+		concurrency: max(cfg.Concurrency, 1),
+		hosts:       newHostLimiter(cfg.PerHost, cfg.HostDelay),
 	}
 }
 
-// FetchAll fetches articles from all provided feed URLs concurrently
-func (f *Fetcher) FetchAll(feedURLs []string) ([]models.Article, error) {
+// FetchAll fetches articles from all provided feeds with a bounded pool of workers,
+// limiting the requests in flight to each host. Feeds with saved validators are
+// requested conditionally; unchanged feeds contribute no articles. The per-feed results
+// carry the validators to save for the next run. Cancelling ctx stops the fetch stage:
+// feeds not yet fetched fail with the context's error, which FetchAll returns.
+func (f *Fetcher) FetchAll(ctx context.Context, feeds []Feed) ([]models.Article, []FeedResult, error) {
 	var wg sync.WaitGroup
-	articlesChan := make(chan []models.Article, len(feedURLs))
-	errorsChan := make(chan error, len(feedURLs))
+	articlesChan := make(chan []models.Article, len(feeds))
+	results := make([]FeedResult, len(feeds))
+	jobs := make(chan int)
 
-	for _, url := range feedURLs {
+	for range min(f.concurrency, len(feeds)) {
 		wg.Add(1)
-		go func(feedURL string) {
+		go func() {
 			defer wg.Done()
-
-			articles, err := f.fetchSingle(feedURL)
-			if err != nil {
-				errorsChan <- fmt.Errorf("error fetching %s: %w", feedURL, err)
-				return
+			for i := range jobs {
+				articles, result := f.fetchSingle(ctx, feeds[i])
+				results[i] = result
+				if result.Err == nil {
+					articlesChan <- articles
+				}
 			}
-			articlesChan <- articles
-		}(url)
+		}()
 	}
 
+	dispatched := make([]bool, len(feeds))
+dispatch:
+	for _, i := range interleaveByHost(feeds) {
+		select {
+		case jobs <- i:
+			dispatched[i] = true
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+
 	wg.Wait()
 	close(articlesChan)
-	close(errorsChan)
+
+	for i, feed := range feeds {
+		if !dispatched[i] {
+			results[i] = FeedResult{Feed: feed, Err: ctx.Err()}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, results, fmt.Errorf("fetch cancelled: %w", err)
+	}
 
 	// Collect all articles
 	var allArticles []models.Article
@@ -53,15 +116,24 @@ func (f *Fetcher) FetchAll(feedURLs []string) ([]models.Article, error) {
 
 	// Check for errors (non-fatal, just log them)
 	var errors []error
-	for err := range errorsChan {
-		errors = append(errors, err)
+	notModified := 0
+	var downloaded int64
+	for _, result := range results {
+		downloaded += result.Bytes
+		if result.NotModified {
+			notModified++
+		}
+		if result.Err != nil {
+			errors = append(errors, fmt.Errorf("error fetching %s: %w", result.Feed.URL, result.Err))
+		}
 	}
 
-	successCount := len(feedURLs) - len(errors)
-	log.Printf("\nFetch summary: %d/%d feeds successful, %d failed", successCount, len(feedURLs), len(errors))
+	successCount := len(feeds) - len(errors)
+	log.Printf("\nFetch summary: %d/%d feeds successful (%d not modified), %d failed, %s downloaded",
+		successCount, len(feeds), notModified, len(errors), FormatBytes(downloaded))
 
-	if len(errors) > 0 && len(allArticles) == 0 {
-		return nil, fmt.Errorf("all feeds failed: %v", errors)
+	if len(errors) > 0 && len(allArticles) == 0 && notModified == 0 {
+		return nil, results, fmt.Errorf("all feeds failed: %v", errors)
 	}
 
 	// Deduplicate by URL
@@ -69,24 +141,94 @@ func (f *Fetcher) FetchAll(feedURLs []string) ([]models.Article, error) {
 	allArticles = deduplicateArticles(allArticles)
 	log.Printf("Deduplication: %d articles → %d unique articles\n", beforeDedup, len(allArticles))
 
-	return allArticles, nil
+	return allArticles, results, nil
 }
 
-// fetchSingle fetches and parses a single RSS feed
-func (f *Fetcher) fetchSingle(feedURL string) ([]models.Article, error) {
-	feed, err := f.parser.ParseURL(feedURL)
+// fetchSingle fetches and parses a single RSS feed, sending its validators so an
+// unchanged feed is answered with 304 Not Modified
+func (f *Fetcher) fetchSingle(ctx context.Context, feed Feed) (articles []models.Article, result FeedResult) {
+	result.Feed = feed
+
+	release, err := f.hosts.acquire(ctx, feedHost(feed.URL))
 	if err != nil {
-		log.Printf("  ✗ Failed to fetch %s: %v", feedURL, err)
-		return nil, err
+		result.Err = err
+		return nil, result
 	}
+	defer release()
+
+	// Latency is measured from the request start, not including time queued for the host
+	start := time.Now()
+	defer func() { result.Latency = time.Since(start) }()
 
-	articles := make([]models.Article, 0, len(feed.Items))
-	for _, item := range feed.Items {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feed.URL, nil)
+	if err != nil {
+		result.Err = err
+		return nil, result
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	if feed.ETag != "" {
+		req.Header.Set("If-None-Match", feed.ETag)
+	}
+	if feed.LastModified != "" {
+		req.Header.Set("If-Modified-Since", feed.LastModified)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		log.Printf("  ✗ Failed to fetch %s: %v", feed.URL, err)
+		result.Err = err
+		return nil, result
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			result.Feed.ETag = etag
+		}
+		if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+			result.Feed.LastModified = lastModified
+		}
+		result.NotModified = true
+		result.validatorsChanged = result.Feed != feed
+		log.Printf("  · Not modified: %s", feed.URL)
+		return nil, result
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("  ✗ Failed to fetch %s: unexpected status %s", feed.URL, resp.Status)
+		result.Err = fmt.Errorf("unexpected status %s", resp.Status)
+		return nil, result
+	}
+
+	// Read one byte past the limit to tell a feed of exactly the maximum size from a larger one
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBody+1))
+	result.Bytes = int64(len(body))
+	if err == nil && result.Bytes > f.maxBody {
+		err = fmt.Errorf("feed is larger than the %s limit", FormatBytes(f.maxBody))
+	}
+	if err != nil {
+		log.Printf("  ✗ Failed to fetch %s: %v", feed.URL, err)
+		result.Err = err
+		return nil, result
+	}
+
+	parsed, err := f.parser.Parse(bytes.NewReader(body))
+	if err != nil {
+		log.Printf("  ✗ Failed to parse %s: %v", feed.URL, err)
+		result.Err = err
+		return nil, result
+	}
+	// Validators are only kept once the feed parsed, so a broken response is fetched again
+	result.Feed.ETag = resp.Header.Get("ETag")
+	result.Feed.LastModified = resp.Header.Get("Last-Modified")
+	result.validatorsChanged = result.Feed != feed
+
+	articles = make([]models.Article, 0, len(parsed.Items))
+	for _, item := range parsed.Items {
 		article := models.Article{
 			Title:       item.Title,
 			Description: item.Description,
 			Link:        item.Link,
-			Source:      feed.Title,
+			Source:      parsed.Title,
 		}
 
 		// Set published time
@@ -104,8 +246,21 @@ func (f *Fetcher) fetchSingle(feedURL string) ([]models.Article, error) {
 		articles = append(articles, article)
 	}
 
-	log.Printf("  ✓ Fetched %d articles from %s", len(articles), feed.Title)
-	return articles, nil
+	result.Articles = len(articles)
+	log.Printf("  ✓ Fetched %d articles from %s (%s)", len(articles), parsed.Title, FormatBytes(result.Bytes))
+	return articles, result
+}
+
+// FormatBytes formats a byte count for logs, e.g. "12.3 KB"
+func FormatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
 }
 
 // deduplicateArticles removes duplicate articles based on URL
@@ -0,0 +1,339 @@
+package feeds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+const testLastModified = "Mon, 06 Oct 2025 08:00:00 GMT"
+
+// conditionalHandler serves body with the given validators, answering 304 Not Modified
+// to requests whose validators match, and records the validators each request sent
+func conditionalHandler(etag, lastModified, body string, sent *[]Feed) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		inm, ims := r.Header.Get("If-None-Match"), r.Header.Get("If-Modified-Since")
+		*sent = append(*sent, Feed{ETag: inm, LastModified: ims})
+
+		if etag != "" {
+			w.Header().Set("ETag", etag)
+		}
+		if lastModified != "" {
+			w.Header().Set("Last-Modified", lastModified)
+		}
+		// As in RFC 9110, If-None-Match takes precedence over If-Modified-Since
+		if (inm != "" && inm == etag) || (inm == "" && ims != "" && ims == lastModified) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte(body))
+	}
+}
+
+func TestFetchSingleConditional(t *testing.T) {
+	tests := []struct {
+		name            string
+		saved           Feed   // Validators saved from the last fetch
+		etag            string // Served validators
+		lastModified    string
+		body            string
+		wantNotModified bool
+		wantArticles    int
+		wantErr         bool
+		wantFeed        Feed // Validators to save
+		wantChanged     bool
+	}{
+		{
+			name:         "first fetch saves validators",
+			etag:         `"v1"`,
+			lastModified: testLastModified,
+			body:         testRSS("Blog"),
+			wantArticles: 1,
+			wantFeed:     Feed{ETag: `"v1"`, LastModified: testLastModified},
+			wantChanged:  true,
+		},
+		{
+			name:            "matching ETag answered 304",
+			saved:           Feed{ETag: `"v1"`, LastModified: testLastModified},
+			etag:            `"v1"`,
+			lastModified:    testLastModified,
+			body:            testRSS("Blog"),
+			wantNotModified: true,
+			wantFeed:        Feed{ETag: `"v1"`, LastModified: testLastModified},
+		},
+		{
+			name:            "matching Last-Modified answered 304",
+			saved:           Feed{LastModified: testLastModified},
+			lastModified:    testLastModified,
+			body:            testRSS("Blog"),
+			wantNotModified: true,
+			wantFeed:        Feed{LastModified: testLastModified},
+		},
+		{
+			name:            "304 with a new ETag updates validators",
+			saved:           Feed{LastModified: testLastModified},
+			etag:            `"v2"`,
+			lastModified:    testLastModified,
+			body:            testRSS("Blog"),
+			wantNotModified: true,
+			wantFeed:        Feed{ETag: `"v2"`, LastModified: testLastModified},
+			wantChanged:     true,
+		},
+		{
+			name:         "stale ETag fetched again",
+			saved:        Feed{ETag: `"v1"`},
+			etag:         `"v2"`,
+			body:         testAtom("Blog"),
+			wantArticles: 2,
+			wantFeed:     Feed{ETag: `"v2"`},
+			wantChanged:  true,
+		},
+		{
+			name:         "validators dropped by the server",
+			saved:        Feed{ETag: `"v1"`},
+			body:         testRSS("Blog"),
+			wantArticles: 1,
+			wantChanged:  true,
+		},
+		{
+			name:     "unparseable feed keeps the saved validators",
+			saved:    Feed{ETag: `"v1"`},
+			etag:     `"v2"`,
+			body:     "<html>not a feed</html>",
+			wantErr:  true,
+			wantFeed: Feed{ETag: `"v1"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []Feed
+			server := httptest.NewServer(conditionalHandler(tt.etag, tt.lastModified, tt.body, &sent))
+			defer server.Close()
+
+			fetcher := NewFetcher(models.FetchConfig{Timeout: 5 * time.Second, MaxBodyBytes: 1 << 20})
+			feed := tt.saved
+			feed.URL = server.URL + "/feed"
+			articles, result := fetcher.fetchSingle(context.Background(), feed)
+
+			if len(sent) != 1 || sent[0].ETag != tt.saved.ETag || sent[0].LastModified != tt.saved.LastModified {
+				t.Errorf("validators sent = %+v, want %+v", sent, tt.saved)
+			}
+			if (result.Err != nil) != tt.wantErr {
+				t.Fatalf("fetch error = %v, want error %v", result.Err, tt.wantErr)
+			}
+			if result.NotModified != tt.wantNotModified {
+				t.Errorf("NotModified = %v, want %v", result.NotModified, tt.wantNotModified)
+			}
+			if len(articles) != tt.wantArticles || result.Articles != tt.wantArticles {
+				t.Errorf("got %d articles (result says %d), want %d", len(articles), result.Articles, tt.wantArticles)
+			}
+			wantFeed := tt.wantFeed
+			wantFeed.URL = feed.URL
+			if result.Feed != wantFeed {
+				t.Errorf("validators to save = %+v, want %+v", result.Feed, wantFeed)
+			}
+			if result.validatorsChanged != tt.wantChanged {
+				t.Errorf("validatorsChanged = %v, want %v", result.validatorsChanged, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestFetchAllConditional(t *testing.T) {
+	var unchangedSent, changedSent []Feed
+	mux := http.NewServeMux()
+	mux.Handle("/unchanged", conditionalHandler(`"v1"`, "", testRSS("Unchanged"), &unchangedSent))
+	mux.Handle("/changed", conditionalHandler(`"v2"`, "", testAtom("Changed"), &changedSent))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	fetcher := NewFetcher(models.FetchConfig{Timeout: 5 * time.Second, MaxBodyBytes: 1 << 20})
+	feeds := []Feed{
+		{URL: server.URL + "/unchanged", ETag: `"v1"`},
+		{URL: server.URL + "/changed", ETag: `"v1"`},
+	}
+	articles, results, err := fetcher.FetchAll(context.Background(), feeds)
+	if err != nil {
+		t.Fatalf("FetchAll: %v", err)
+	}
+
+	// Only the changed feed contributes articles
+	if len(articles) != 2 || articles[0].Source != "Changed" {
+		t.Errorf("got %d articles %+v, want the changed feed's 2", len(articles), articles)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if !results[0].NotModified || results[0].Bytes != 0 || results[0].Feed.ETag != `"v1"` {
+		t.Errorf("unchanged feed result = %+v, want not modified with nothing downloaded", results[0])
+	}
+	if results[1].NotModified || results[1].Articles != 2 || results[1].Feed.ETag != `"v2"` {
+		t.Errorf("changed feed result = %+v, want 2 articles and the new ETag", results[1])
+	}
+}
+
+func TestFetchSingleBodyLimit(t *testing.T) {
+	body := testRSS("Blog")
+	limit := int64(len(body))
+
+	tests := []struct {
+		name     string
+		maxBody  int64
+		wantErr  bool
+		wantRead int64
+	}{
+		{name: "feed at the limit", maxBody: limit, wantRead: limit},
+		{name: "feed one byte over the limit", maxBody: limit - 1, wantErr: true, wantRead: limit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			fetcher := NewFetcher(models.FetchConfig{Timeout: 5 * time.Second, MaxBodyBytes: tt.maxBody})
+			articles, result := fetcher.fetchSingle(context.Background(), Feed{URL: server.URL})
+			if (result.Err != nil) != tt.wantErr {
+				t.Fatalf("fetch error = %v, want error %v", result.Err, tt.wantErr)
+			}
+			if tt.wantErr && len(articles) != 0 {
+				t.Errorf("oversized feed returned %d articles", len(articles))
+			}
+			// Reading stops one byte past the limit
+			if result.Bytes != tt.wantRead {
+				t.Errorf("read %d bytes, want %d", result.Bytes, tt.wantRead)
+			}
+		})
+	}
+
+	t.Run("large feed not read in full", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(strings.Repeat("x", 1<<20)))
+		}))
+		defer server.Close()
+
+		fetcher := NewFetcher(models.FetchConfig{Timeout: 5 * time.Second, MaxBodyBytes: 1024})
+		_, result := fetcher.fetchSingle(context.Background(), Feed{URL: server.URL})
+		if result.Err == nil || !strings.Contains(result.Err.Error(), "larger than the 1.0 KB limit") {
+			t.Errorf("fetch error = %v, want the size limit error", result.Err)
+		}
+		if result.Bytes != 1025 {
+			t.Errorf("read %d bytes, want 1025", result.Bytes)
+		}
+	})
+}
+
+func TestFetchAllPerHostLimit(t *testing.T) {
+	const perHost = 2
+
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := peak.Load()
+			if current <= seen || peak.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(testRSS("Feed " + r.URL.Path)))
+	}))
+	defer server.Close()
+
+	feedList := make([]Feed, 8)
+	for i := range feedList {
+		feedList[i] = Feed{URL: fmt.Sprintf("%s/feed/%d", server.URL, i)}
+	}
+
+	fetcher := NewFetcher(models.FetchConfig{Timeout: 5 * time.Second, MaxBodyBytes: 1 << 20, Concurrency: 8, PerHost: perHost})
+	_, results, err := fetcher.FetchAll(context.Background(), feedList)
+	if err != nil {
+		t.Fatalf("FetchAll: %v", err)
+	}
+	for i, result := range results {
+		if result.Err != nil {
+			t.Errorf("feed %d failed: %v", i, result.Err)
+		}
+	}
+	if got := peak.Load(); got > perHost {
+		t.Errorf("%d requests to one host at once, want at most %d", got, perHost)
+	}
+	if got := peak.Load(); got < perHost {
+		t.Errorf("at most %d requests to the host at once, want the workers to use all %d slots", got, perHost)
+	}
+}
+
+func TestHostLimiterDelay(t *testing.T) {
+	const delay = 30 * time.Millisecond
+	limiter := newHostLimiter(4, delay)
+
+	var starts []time.Time
+	for range 3 {
+		release, err := limiter.acquire(context.Background(), "example.com")
+		if err != nil {
+			t.Fatalf("acquire: %v", err)
+		}
+		starts = append(starts, time.Now())
+		release()
+	}
+	for i := 1; i < len(starts); i++ {
+		if gap := starts[i].Sub(starts[i-1]); gap < delay-5*time.Millisecond {
+			t.Errorf("request %d started %v after the previous one, want at least %v", i+1, gap, delay)
+		}
+	}
+
+	// Other hosts are not held up
+	start := time.Now()
+	release, err := limiter.acquire(context.Background(), "other.example.com")
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	release()
+	if wait := time.Since(start); wait > delay/2 {
+		t.Errorf("first request to another host waited %v", wait)
+	}
+}
+
+func TestHostLimiterCancelled(t *testing.T) {
+	limiter := newHostLimiter(1, 0)
+	release, err := limiter.acquire(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.acquire(ctx, "example.com"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquire on a full host = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestInterleaveByHost(t *testing.T) {
+	feedList := []Feed{
+		{URL: "https://a.example.com/1"},
+		{URL: "https://a.example.com/2"},
+		{URL: "https://b.example.com/1"},
+		{URL: "https://a.example.com/3"},
+		{URL: "https://c.example.com/1"},
+		{URL: "https://c.example.com/2"},
+	}
+	// Hosts with more feeds go first in each round
+	want := []int{0, 4, 2, 1, 5, 3}
+	if got := interleaveByHost(feedList); !reflect.DeepEqual(got, want) {
+		t.Errorf("interleaveByHost = %v, want %v", got, want)
+	}
+}
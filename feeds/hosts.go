@@ -0,0 +1,110 @@
+package feeds
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// hostLimiter bounds the requests in flight to each host and spaces out their starts
+type hostLimiter struct {
+	perHost int
+	delay   time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+// hostState is the concurrency slots and next allowed request start of one host
+type hostState struct {
+	slots chan struct{}
+	mu    sync.Mutex
+	next  time.Time
+}
+
+func newHostLimiter(perHost int, delay time.Duration) *hostLimiter {
+	return &hostLimiter{
+		perHost: max(perHost, 1),
+		delay:   delay,
+		hosts:   make(map[string]*hostState),
+	}
+}
+
+// acquire waits for a free slot for host and for the host's minimum delay to pass. The
+// returned function releases the slot.
+func (l *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	l.mu.Lock()
+	state, ok := l.hosts[host]
+	if !ok {
+		state = &hostState{slots: make(chan struct{}, l.perHost)}
+		l.hosts[host] = state
+	}
+	l.mu.Unlock()
+
+	select {
+	case state.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := func() { <-state.slots }
+
+	// Reserve the next start time, so concurrent callers queue up behind each other
+	state.mu.Lock()
+	start := time.Now()
+	if state.next.After(start) {
+		start = state.next
+	}
+	state.next = start.Add(l.delay)
+	state.mu.Unlock()
+
+	if wait := time.Until(start); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
+
+// feedHost returns the host a feed URL is requested from
+func feedHost(feedURL string) string {
+	parsed, err := url.Parse(feedURL)
+	if err != nil {
+		return feedURL
+	}
+	return parsed.Host
+}
+
+// interleaveByHost returns the indices of feeds ordered round-robin across hosts, so
+// workers are not all held up waiting on one busy host
+func interleaveByHost(feeds []Feed) []int {
+	byHost := make(map[string][]int)
+	var hosts []string
+	for i, feed := range feeds {
+		host := feedHost(feed.URL)
+		if _, ok := byHost[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		byHost[host] = append(byHost[host], i)
+	}
+	// Hosts with the most feeds go first, since they take longest to get through
+	sort.SliceStable(hosts, func(i, j int) bool {
+		return len(byHost[hosts[i]]) > len(byHost[hosts[j]])
+	})
+
+	order := make([]int, 0, len(feeds))
+	for round := 0; len(order) < len(feeds); round++ {
+		for _, host := range hosts {
+			if round < len(byHost[host]) {
+				order = append(order, byHost[host][round])
+			}
+		}
+	}
+	return order
+}
@@ -0,0 +1,116 @@
+package feeds
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OPMLFeed is a feed listed in an OPML file
+type OPMLFeed struct {
+	Title    string
+	URL      string
+	Category string // Text of the outline group the feed is in; empty outside any group
+}
+
+type opmlDocument struct {
+	XMLName xml.Name      `xml:"opml"`
+	Version string        `xml:"version,attr"`
+	Title   string        `xml:"head>title,omitempty"`
+	Created string        `xml:"head>dateCreated,omitempty"`
+	Body    []opmlOutline `xml:"body>outline"`
+}
+
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr,omitempty"`
+	Type     string        `xml:"type,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr,omitempty"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// ParseOPML reads the feeds of an OPML subscription list. Outlines with an xmlUrl are
+// feeds; outlines without one are groups, and a feed's category is the innermost group
+// it is nested in.
+func ParseOPML(r io.Reader) ([]OPMLFeed, error) {
+	var doc opmlDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OPML: %w", err)
+	}
+
+	var feeds []OPMLFeed
+	var walk func(outlines []opmlOutline, category string)
+	walk = func(outlines []opmlOutline, category string) {
+		for _, outline := range outlines {
+			title := strings.TrimSpace(outline.Title)
+			if title == "" {
+				title = strings.TrimSpace(outline.Text)
+			}
+			if url := strings.TrimSpace(outline.XMLURL); url != "" {
+				feeds = append(feeds, OPMLFeed{Title: title, URL: url, Category: category})
+				continue
+			}
+			walk(outline.Outlines, title)
+		}
+	}
+	walk(doc.Body, "")
+
+	if len(feeds) == 0 {
+		return nil, fmt.Errorf("OPML file has no feeds")
+	}
+	return feeds, nil
+}
+
+// WriteOPML writes feeds as an OPML 2.0 subscription list with one outline group per
+// category, sorted by category and title. Feeds without a category are listed outside
+// any group.
+func WriteOPML(w io.Writer, title string, feeds []OPMLFeed) error {
+	byCategory := make(map[string][]OPMLFeed)
+	for _, feed := range feeds {
+		byCategory[feed.Category] = append(byCategory[feed.Category], feed)
+	}
+	categories := make([]string, 0, len(byCategory))
+	for category := range byCategory {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	doc := opmlDocument{
+		Version: "2.0",
+		Title:   title,
+		Created: time.Now().UTC().Format(time.RFC1123Z),
+	}
+	for _, category := range categories {
+		sort.SliceStable(byCategory[category], func(i, j int) bool {
+			return byCategory[category][i].Title < byCategory[category][j].Title
+		})
+		var outlines []opmlOutline
+		for _, feed := range byCategory[category] {
+			outlines = append(outlines, opmlOutline{
+				Text:   feed.Title,
+				Title:  feed.Title,
+				Type:   "rss",
+				XMLURL: feed.URL,
+			})
+		}
+		if category == "" {
+			doc.Body = append(doc.Body, outlines...)
+			continue
+		}
+		doc.Body = append(doc.Body, opmlOutline{Text: category, Title: category, Outlines: outlines})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write OPML: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,132 @@
+package feeds
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseOPML(t *testing.T) {
+	tests := []struct {
+		name    string
+		opml    string
+		want    []OPMLFeed
+		wantErr bool
+	}{
+		{
+			name: "flat outlines",
+			opml: `<opml version="1.0"><body>
+				<outline text="Go Blog" type="rss" xmlUrl="https://go.dev/blog/feed.atom"/>
+				<outline text="LWN" type="rss" xmlUrl="https://lwn.net/headlines/rss"/>
+			</body></opml>`,
+			want: []OPMLFeed{
+				{Title: "Go Blog", URL: "https://go.dev/blog/feed.atom"},
+				{Title: "LWN", URL: "https://lwn.net/headlines/rss"},
+			},
+		},
+		{
+			name: "grouped outlines",
+			opml: `<opml version="2.0"><body>
+				<outline text="Backend">
+					<outline text="Go Blog" type="rss" xmlUrl="https://go.dev/blog/feed.atom"/>
+				</outline>
+				<outline text="Security">
+					<outline text="Krebs" type="rss" xmlUrl="https://krebsonsecurity.com/feed/"/>
+				</outline>
+				<outline text="LWN" type="rss" xmlUrl="https://lwn.net/headlines/rss"/>
+			</body></opml>`,
+			want: []OPMLFeed{
+				{Title: "Go Blog", URL: "https://go.dev/blog/feed.atom", Category: "Backend"},
+				{Title: "Krebs", URL: "https://krebsonsecurity.com/feed/", Category: "Security"},
+				{Title: "LWN", URL: "https://lwn.net/headlines/rss"},
+			},
+		},
+		{
+			name: "innermost group is the category",
+			opml: `<opml version="2.0"><body>
+				<outline text="Tech">
+					<outline text="Languages">
+						<outline text="Go Blog" type="rss" xmlUrl="https://go.dev/blog/feed.atom"/>
+					</outline>
+					<outline text="Hacker News" type="rss" xmlUrl="https://news.ycombinator.com/rss"/>
+				</outline>
+			</body></opml>`,
+			want: []OPMLFeed{
+				{Title: "Go Blog", URL: "https://go.dev/blog/feed.atom", Category: "Languages"},
+				{Title: "Hacker News", URL: "https://news.ycombinator.com/rss", Category: "Tech"},
+			},
+		},
+		{
+			name: "title preferred over text and whitespace trimmed",
+			opml: `<opml version="2.0"><body>
+				<outline text=" Dev " title=" Development ">
+					<outline text="golang" title=" The Go Blog " xmlUrl=" https://go.dev/blog/feed.atom "/>
+				</outline>
+			</body></opml>`,
+			want: []OPMLFeed{
+				{Title: "The Go Blog", URL: "https://go.dev/blog/feed.atom", Category: "Development"},
+			},
+		},
+		{
+			name:    "no feeds",
+			opml:    `<opml version="2.0"><body><outline text="Empty group"/></body></opml>`,
+			wantErr: true,
+		},
+		{
+			name:    "not OPML",
+			opml:    `<rss version="2.0"><channel><title>A feed</title></channel></rss>`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseOPML(strings.NewReader(tt.opml))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseOPML returned %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseOPML: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseOPML = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteOPMLRoundTrip(t *testing.T) {
+	feeds := []OPMLFeed{
+		{Title: "Krebs", URL: "https://krebsonsecurity.com/feed/", Category: "Security"},
+		{Title: "LWN", URL: "https://lwn.net/headlines/rss"},
+		{Title: "The Go Blog", URL: "https://go.dev/blog/feed.atom", Category: "Backend"},
+		{Title: "Rust Blog", URL: "https://blog.rust-lang.org/feed.xml", Category: "Backend"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteOPML(&buf, "The Paper sources", feeds); err != nil {
+		t.Fatalf("WriteOPML: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "<?xml") {
+		t.Errorf("output does not start with an XML header: %.40q", buf.String())
+	}
+
+	got, err := ParseOPML(&buf)
+	if err != nil {
+		t.Fatalf("ParseOPML: %v", err)
+	}
+	// Feeds come back ordered by category, then title, with ungrouped feeds first
+	want := []OPMLFeed{
+		{Title: "LWN", URL: "https://lwn.net/headlines/rss"},
+		{Title: "Rust Blog", URL: "https://blog.rust-lang.org/feed.xml", Category: "Backend"},
+		{Title: "The Go Blog", URL: "https://go.dev/blog/feed.atom", Category: "Backend"},
+		{Title: "Krebs", URL: "https://krebsonsecurity.com/feed/", Category: "Security"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+}
@@ -1,6 +1,8 @@
 package feeds
 
 import (
+	"context"
+	"errors"
 	"log"
 
 	"github.com/ty-e-boyd/thepaper/database"
@@ -121,8 +123,8 @@ var FeedSources = map[string][]string{
 	},
 }
 
-// GetAllFeeds returns a flat list of all active feed URLs from the database
-func GetAllFeeds() []string {
+// GetAllFeeds returns all active feeds from the database, with their saved validators
+func GetAllFeeds() []Feed {
 	sources, err := database.GetAllActiveSources()
 	if err != nil {
 		log.Fatalf("Failed to get sources from database: %v", err)
@@ -132,14 +134,27 @@ func GetAllFeeds() []string {
 		log.Fatalf("No active sources found in database. Run 'cd scripts && go run seed_sources.go' to populate sources.")
 	}
 
-	var allFeeds []string
+	var allFeeds []Feed
 	for _, source := range sources {
-		allFeeds = append(allFeeds, source.URL)
+		allFeeds = append(allFeeds, Feed{URL: source.URL, ETag: source.ETag, LastModified: source.LastModified})
 	}
 
 	return allFeeds
 }
 
+// SaveValidators stores the validators of successfully fetched feeds whose values
+// changed, so the next run can request them conditionally
+func SaveValidators(results []FeedResult) {
+	for _, result := range results {
+		if result.Err != nil || !result.validatorsChanged {
+			continue
+		}
+		if err := database.UpdateSourceValidators(result.Feed.URL, result.Feed.ETag, result.Feed.LastModified); err != nil {
+			log.Printf("Warning: Failed to save validators for %s: %v", result.Feed.URL, err)
+		}
+	}
+}
+
 // GetFeedsByCategory returns feeds for a specific category from the database
 func GetFeedsByCategory(category string) []string {
 	sources, err := database.GetSourcesByCategory(category)
@@ -173,3 +188,28 @@ func GetCategories() []string {
 	}
 	return categories
 }
+
+// RecordHealth updates every fetched source's health and deactivates sources that have
+// failed maxFailures times in a row (0 never deactivates). Feeds whose fetch was
+// cancelled are skipped, since their failure says nothing about the source.
+func RecordHealth(results []FeedResult, maxFailures int) {
+	for _, result := range results {
+		if errors.Is(result.Err, context.Canceled) {
+			continue
+		}
+		fetch := database.SourceFetch{
+			Err:         result.Err,
+			Items:       result.Articles,
+			NotModified: result.NotModified,
+			Latency:     result.Latency,
+		}
+		quarantined, err := database.RecordSourceFetch(result.Feed.URL, fetch, maxFailures)
+		if err != nil {
+			log.Printf("Warning: Failed to record health for %s: %v", result.Feed.URL, err)
+			continue
+		}
+		if quarantined {
+			log.Printf("  ⚠ Deactivated %s after %d consecutive failures (last error: %v)", result.Feed.URL, maxFailures, result.Err)
+		}
+	}
+}
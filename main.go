@@ -2,9 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -14,15 +18,16 @@ import (
 	"github.com/ty-e-boyd/thepaper/email"
 	"github.com/ty-e-boyd/thepaper/feeds"
 	"github.com/ty-e-boyd/thepaper/models"
-)
-
-const (
-	topArticlesCount = 8 // Number of top articles to include in the email
+	"github.com/ty-e-boyd/thepaper/report"
 )
 
 func main() {
 	// Parse command-line flags
 	dryRun := flag.Bool("dry-run", false, "Run without sending emails (preview mode)")
+	recordPath := flag.String("record", "", "Record the run's articles and LLM responses to this cassette file")
+	replayPath := flag.String("replay", "", "Re-run the analysis from a recorded cassette without network access (implies --dry-run)")
+	reportDir := flag.String("report", "", "Write a report explaining each article's selection to this directory (dry runs default to reports)")
+	flag.Usage = usage
 	flag.Parse()
 
 	// Load .env file
@@ -30,11 +35,39 @@ func main() {
 		log.Println("No .env file found, using environment variables")
 	}
 
+	// Run a maintenance command instead of the newsletter if one was given
+	if flag.NArg() > 0 {
+		runCommand(flag.Args())
+		return
+	}
+
+	if *recordPath != "" && *replayPath != "" {
+		log.Fatalf("--record and --replay cannot be used together")
+	}
+
+	// Replaying answers every LLM call from the cassette, so a missing prompt fails the run
+	var cassette *ai.Cassette
+	if *replayPath != "" {
+		var err error
+		cassette, err = ai.LoadCassette(*replayPath)
+		if err != nil {
+			log.Fatalf("Failed to load cassette: %v", err)
+		}
+		*dryRun = true
+	} else if *recordPath != "" {
+		cassette = ai.NewCassette()
+	}
+
 	if *dryRun {
 		log.Println("🔍 DRY RUN MODE - No emails will be sent")
+		if *reportDir == "" {
+			*reportDir = "reports"
+		}
 	}
 
-	ctx := context.Background()
+	// An interrupt cancels the run, stopping the feed downloads and LLM calls in flight
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
 	// Connect to database
 	log.Println("Connecting to database...")
@@ -50,78 +83,51 @@ func main() {
 
 	// Load configuration
 	log.Println("Loading configuration...")
-	cfg, err := config.Load()
+	var cfg *models.Config
+	var err error
+	if *replayPath != "" {
+		cfg, err = config.LoadAI()
+	} else {
+		cfg, err = config.Load()
+	}
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
-
-	// Get subscribed users from database
-	users, err := database.GetAllSubscribedUsers()
+	fetchCfg, err := config.LoadFetch()
 	if err != nil {
-		log.Fatalf("Failed to get subscribed users: %v", err)
-	}
-	if len(users) == 0 {
-		log.Println("No subscribed users found, exiting")
-		return
+		log.Fatalf("Failed to load config: %v", err)
 	}
-	log.Printf("Found %d subscribed user(s)", len(users))
 
-	// Fetch articles from RSS feeds (now pulls from database)
-	feedURLs := feeds.GetAllFeeds()
-	log.Printf("Fetching articles from %d feeds from database across %d categories...", len(feedURLs), len(feeds.GetCategories()))
-	fetcher := feeds.NewFetcher()
-	articles, err := fetcher.FetchAll(feedURLs)
+	// Get daily subscribers from database; weekly subscribers get the roundup instead
+	users, err := subscribedUsers(false)
 	if err != nil {
-		log.Fatalf("Failed to fetch articles: %v", err)
+		log.Fatalf("Failed to get subscribed users: %v", err)
 	}
-	log.Printf("Fetched %d articles", len(articles))
-
-	if len(articles) == 0 {
-		log.Println("No articles found, exiting")
+	if len(users) == 0 {
+		log.Println("No daily subscribers found, exiting")
 		return
 	}
-
-	// Filter articles to last 24 hours
-	cutoff := time.Now().Add(-24 * time.Hour)
-	var recentArticles []models.Article
-	for _, article := range articles {
-		if article.Published.After(cutoff) || article.Published.IsZero() {
-			recentArticles = append(recentArticles, article)
+	log.Printf("Found %d daily subscriber(s)", len(users))
+
+	// Use the recorded run's articles when replaying, otherwise fetch new ones
+	fetcher := feeds.NewFetcher(fetchCfg)
+	trail := report.NewTrail()
+	var articles []models.Article
+	var fetchResults []feeds.FeedResult
+	if *replayPath != "" {
+		articles = cassette.Articles()
+		if len(articles) == 0 {
+			log.Fatalf("Cassette %s has no recorded articles", *replayPath)
 		}
-	}
-	log.Printf("Filtered to %d articles from last 24 hours (from %d total)\n", len(recentArticles), len(articles))
-	articles = recentArticles
-
-	if len(articles) == 0 {
-		log.Println("No recent articles found, exiting")
-		return
-	}
-
-	// Filter out articles sent in the last 30 days
-	recentArticleURLs, err := database.GetRecentArticleURLs(30)
-	if err != nil {
-		log.Printf("Warning: Failed to get recent article URLs: %v", err)
-		recentArticleURLs = make(map[string]bool)
-	}
-
-	var newArticles []models.Article
-	for _, article := range articles {
-		if !recentArticleURLs[article.Link] {
-			newArticles = append(newArticles, article)
+		log.Printf("Replaying %d recorded articles and %d responses from %s", len(articles), cassette.Len(), *replayPath)
+		trail.Fetched(articles)
+	} else {
+		articles, fetchResults = fetchNewArticles(ctx, fetcher, trail)
+		if len(articles) == 0 {
+			return
 		}
 	}
 
-	duplicatesFiltered := len(articles) - len(newArticles)
-	if duplicatesFiltered > 0 {
-		log.Printf("Filtered out %d duplicate articles sent in the last 30 days", duplicatesFiltered)
-	}
-	articles = newArticles
-
-	if len(articles) == 0 {
-		log.Println("No new articles found (all were sent recently), exiting")
-		return
-	}
-
 	// Show article distribution by source
 	sourceCount := make(map[string]int)
 	for _, article := range articles {
@@ -133,58 +139,152 @@ func main() {
 	}
 	log.Println()
 
-	// Analyze and select top articles using Gemini
-	log.Printf("Analyzing articles with Gemini AI (rate limit: %v)...\n", cfg.GeminiRateLimit)
-	analyzer, err := ai.NewAnalyzer(ctx, cfg.GeminiAPIKey, cfg.GeminiRateLimit)
+	// Analyze and select top articles using the configured LLM provider
+	var provider ai.Provider
+	if *replayPath != "" {
+		provider = ai.NewReplayProvider(cassette)
+		cfg.AIRequestsPerMinute = 0
+		cfg.AITokensPerMinute = 0
+		cfg.AIRetryMaxAttempts = 1
+	} else {
+		provider, err = ai.NewProvider(ctx, cfg)
+		if err != nil {
+			log.Fatalf("Failed to create AI provider: %v", err)
+		}
+		if *recordPath != "" {
+			cassette.SetArticles(articles)
+			provider = ai.NewRecordingProvider(provider, cassette)
+		}
+	}
+	log.Printf("Analyzing articles with %s (%s, %d workers, %d requests/min, %d tokens/min)...\n",
+		provider.Name(), provider.Model(), cfg.AIConcurrency, cfg.AIRequestsPerMinute, cfg.AITokensPerMinute)
+	prompts, err := ai.LoadPrompts(cfg.PromptsDir)
 	if err != nil {
-		log.Fatalf("Failed to create analyzer: %v", err)
+		log.Fatalf("Failed to load prompts: %v", err)
 	}
+	analyzer := ai.NewAnalyzer(provider, cfg)
 	defer analyzer.Close()
+	analyzer.SetPrompts(prompts)
+	analyzer.SetSummaryStyles(summaryStylesFor(users, cfg.SummaryStyle))
+	analyzer.SetDecisionRecorder(trail)
+	// Cached results would be missing from a recording, so the cache is skipped with cassettes
+	if cfg.LLMCacheTTL > 0 && cassette == nil {
+		analyzer.SetCache(database.NewLLMCache(cfg.LLMCacheTTL))
+	}
+	// The score history changes between runs, so runs with a cassette calibrate against
+	// their own scores and a replay reproduces the recorded run. Dry runs read the
+	// history but leave it unchanged.
+	if cfg.ScoreCalibration != ai.CalibrationOff && cassette == nil {
+		var history ai.ScoreHistory = database.NewScoreHistory(time.Duration(cfg.ScoreHistoryDays) * 24 * time.Hour)
+		if *dryRun {
+			history = ai.ReadOnlyHistory(history)
+		}
+		analyzer.SetScoreHistory(history)
+	}
+	// Extracted pages are not recorded, so replays summarize the recorded feed content
+	if cfg.ExtractFullText && cassette == nil {
+		analyzer.SetContentExtractor(fetcher.NewExtractor(cfg.ExtractMaxChars))
+	}
+	if cfg.AIDailyBudgetUSD > 0 && *replayPath == "" {
+		now := time.Now()
+		spent, err := database.GetLLMSpendSince(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()))
+		if err != nil {
+			log.Printf("Warning: Failed to get today's LLM spend, assuming none: %v", err)
+		}
+		analyzer.SetSpentToday(spent)
+		log.Printf("Daily LLM budget: $%.4f of $%.4f spent today (%s mode)", spent, cfg.AIDailyBudgetUSD, cfg.AIBudgetMode)
+	}
+
+	// LLM usage is saved even when the run stops early, so it counts against the daily budget
+	saveUsage := func(emailID *uint) {
+		if *replayPath != "" {
+			return
+		}
+		if err := database.CreateRunStats(emailID, analyzer.Usage()); err != nil {
+			log.Printf("Warning: Failed to save LLM usage: %v", err)
+		}
+	}
 
-	selectedArticles, err := analyzer.SelectAndSummarize(ctx, articles, topArticlesCount)
+	rankedArticles, err := analyzer.RankArticles(ctx, articles, cfg.Selection.TopN)
 	if err != nil {
+		saveUsage(nil)
 		log.Fatalf("Failed to analyze articles: %v", err)
 	}
+
+	selectedArticles, err := analyzer.SelectFromRanked(ctx, rankedArticles, cfg.Selection.TopN)
+	if err != nil {
+		saveUsage(nil)
+		log.Fatalf("Failed to select articles: %v", err)
+	}
 	log.Printf("Selected and summarized %d top articles", len(selectedArticles))
 
+	// Write the editorial intro and subject teaser for the shared selection
+	log.Printf("\nWriting editorial intro...")
+	editorial, err := analyzer.WriteIntro(ctx, selectedArticles)
+	if errors.Is(err, ai.ErrBudgetExceeded) {
+		saveUsage(nil)
+		log.Fatalf("Failed to write intro: %v", err)
+	}
+	if err != nil {
+		log.Printf("Warning: Failed to write intro, using the default subject: %v", err)
+	}
+
+	// Re-rank the shared pool for users with an interest profile
+	userArticles := make(map[uint][]models.AnalyzedArticle)
+	for _, user := range users {
+		profile := user.Profile()
+		if profile.IsEmpty() {
+			continue
+		}
+
+		log.Printf("\nPersonalizing selection for %s...", user.Email)
+		personalized, err := analyzer.Personalize(ctx, rankedArticles, profile, cfg.Selection.TopN)
+		if errors.Is(err, ai.ErrBudgetExceeded) {
+			saveUsage(nil)
+			log.Fatalf("Failed to personalize for %s: %v", user.Email, err)
+		}
+		if err != nil {
+			log.Printf("Warning: Failed to personalize for %s, using shared selection: %v", user.Email, err)
+			continue
+		}
+		userArticles[user.ID] = personalized
+	}
+	analyzer.LogStats()
+
+	var reportPath string
+	if *reportDir != "" {
+		jsonPath, htmlPath, err := trail.Report().Write(*reportDir)
+		if err != nil {
+			log.Printf("Warning: Failed to write selection report: %v", err)
+		} else {
+			log.Printf("✓ Wrote selection report to %s and %s", jsonPath, htmlPath)
+			reportPath = htmlPath
+		}
+	}
+
+	if *recordPath != "" {
+		if err := cassette.Save(*recordPath); err != nil {
+			log.Fatalf("Failed to save cassette: %v", err)
+		}
+		log.Printf("✓ Recorded %d articles and %d responses to %s", len(articles), cassette.Len(), *recordPath)
+	}
+
 	// Count unique sources from all fetched articles
 	uniqueSources := make(map[string]bool)
 	for _, article := range articles {
 		uniqueSources[article.Source] = true
 	}
 
-	// Create email record in database
+	// Create email record in database (not for replays, which would duplicate the recorded run)
 	subject := fmt.Sprintf("The Paper - %s", time.Now().Format("January 2, 2006"))
-	emailRecord, err := database.CreateEmailSent(
-		subject,
-		len(articles),
-		len(uniqueSources),
-		len(users),
-	)
-	if err != nil {
-		log.Fatalf("Failed to create email record: %v", err)
+	if editorial.Teaser != "" {
+		subject = fmt.Sprintf("The Paper: %s", editorial.Teaser)
 	}
-	log.Printf("✓ Email record created (ID: %d)", emailRecord.ID)
-
-	// Save selected articles to database
-	for i, article := range selectedArticles {
-		_, err := database.CreateEmailArticle(
-			emailRecord.ID,
-			article.Link,
-			article.Title,
-			article.Source,
-			article.RelevanceScore,
-			article.Category,
-			article.Tags,
-			article.Summary,
-			article.Published,
-			i+1, // position (1-indexed)
-		)
-		if err != nil {
-			log.Printf("Warning: Failed to save article to database: %v", err)
-		}
+	var emailRecord *database.EmailSent
+	if *replayPath == "" {
+		emailRecord = saveEmailRecord(subject, editorial, articles, len(uniqueSources), len(users), selectedArticles, analyzer.PromptVersion())
+		saveUsage(&emailRecord.ID)
 	}
-	log.Printf("✓ Saved %d articles to database", len(selectedArticles))
 
 	// Dry run mode - skip sending
 	if *dryRun {
@@ -192,17 +292,62 @@ func main() {
 		log.Println("🔍 DRY RUN SUMMARY")
 		log.Println("============================================================")
 		log.Printf("📊 Total articles fetched: %d", len(articles))
+		if fetchResults != nil {
+			var downloaded int64
+			notModified := 0
+			for _, result := range fetchResults {
+				downloaded += result.Bytes
+				if result.NotModified {
+					notModified++
+				}
+			}
+			log.Printf("📥 Downloaded: %s from %d feeds (%d not modified)", feeds.FormatBytes(downloaded), len(fetchResults), notModified)
+		}
 		log.Printf("📰 Unique sources: %d", len(uniqueSources))
 		log.Printf("👥 Subscribed users: %d", len(users))
 		log.Printf("⭐ Top articles selected: %d", len(selectedArticles))
+		log.Printf("✉️  Subject: %s", subject)
+		if editorial.Intro != "" {
+			log.Printf("💬 Intro: %s", editorial.Intro)
+		}
+		if reportPath != "" {
+			log.Printf("🧾 Selection report: %s", reportPath)
+		}
+		log.Println("\n💰 LLM usage:")
+		var totalCost float64
+		var totalTokens int
+		for _, stage := range analyzer.Usage() {
+			log.Printf("  • %s: %d requests, %d prompt + %d output tokens, $%.4f",
+				stage.Stage, stage.Requests, stage.PromptTokens, stage.OutputTokens, stage.CostUSD)
+			totalCost += stage.CostUSD
+			totalTokens += stage.PromptTokens + stage.OutputTokens
+		}
+		log.Printf("  Total: %d tokens, $%.4f", totalTokens, totalCost)
 		log.Println("\n📧 Would send to:")
 		for _, user := range users {
-			log.Printf("  • %s (%s)", user.Email, user.Name)
+			log.Printf("  • %s (%s, %s summaries)", user.Email, user.Name, userSummaryStyle(user, cfg.SummaryStyle))
 		}
 		log.Println("\n📝 Selected articles:")
 		for i, article := range selectedArticles {
 			log.Printf("  %d. [%.1f] %s", i+1, article.RelevanceScore, article.Title)
-			log.Printf("     Source: %s | Category: %s", article.Source, article.Category)
+			log.Printf("     Source: %s | Category: %s | Raw score: %.1f", article.Source, article.Category, article.RawScore)
+			if others := article.OtherSources(); len(others) > 0 {
+				sources := make([]string, len(others))
+				for j, related := range others {
+					sources[j] = related.Source
+				}
+				log.Printf("     Also on: %s", strings.Join(sources, ", "))
+			}
+		}
+		for _, user := range users {
+			personalized, ok := userArticles[user.ID]
+			if !ok {
+				continue
+			}
+			log.Printf("\n🎯 Personalized for %s:", user.Email)
+			for i, article := range personalized {
+				log.Printf("  %d. [%.1f] %s (%s)", i+1, article.RelevanceScore, article.Title, article.Category)
+			}
 		}
 		log.Println("\n============================================================")
 		log.Println("✅ Dry run complete - no emails sent")
@@ -218,8 +363,15 @@ func main() {
 	for _, user := range users {
 		log.Printf("Sending email to %s (%s)...", user.Email, user.Name)
 
-		// Build personalized HTML email with unsubscribe token
-		htmlContent := email.BuildHTMLWithToken(selectedArticles, len(articles), len(uniqueSources), user.UnsubscribeToken)
+		// Build personalized HTML email with the user's picks and unsubscribe token.
+		// The intro describes the shared selection, so personalized picks go without it.
+		userSelection, ok := userArticles[user.ID]
+		intro := ""
+		if !ok {
+			userSelection = selectedArticles
+			intro = editorial.Intro
+		}
+		htmlContent := email.BuildHTMLWithToken(userSelection, intro, len(articles), len(uniqueSources), user.UnsubscribeToken, userSummaryStyle(user, cfg.SummaryStyle), emailRecord.ID)
 
 		err = sender.Send(cfg.FromEmail, user.Email, subject, htmlContent)
 		if err != nil {
@@ -228,16 +380,26 @@ func main() {
 			continue
 		}
 
-		// Record that email was sent to this user
+		// Record that email was sent to this user, with their personalized picks so
+		// they are not sent again
 		_, err = database.CreateUserEmail(user.ID, emailRecord.ID)
 		if err != nil {
 			log.Printf("  Warning: Failed to record email send for %s: %v", user.Email, err)
 		}
+		if ok {
+			saveEmailArticles(emailRecord.ID, &user.ID, userSelection, analyzer.PromptVersion())
+		}
 
 		log.Printf("  ✓ Sent successfully to %s", user.Email)
 		successCount++
 	}
 
+	// Unchanged feeds contribute no articles from now on, so validators are saved only
+	// once the issue has reached a subscriber
+	if successCount > 0 {
+		feeds.SaveValidators(fetchResults)
+	}
+
 	log.Println("\n============================================================")
 	log.Printf("Email campaign complete!")
 	log.Printf("Successfully sent: %d", successCount)
@@ -246,3 +408,169 @@ func main() {
 	log.Printf("Articles featured: %d", len(selectedArticles))
 	log.Printf("============================================================")
 }
+
+// fetchNewArticles fetches articles from all active feeds and keeps those published in
+// the last 24 hours that were not sent in the last 30 days, recording what was dropped
+// on the trail. Each source's fetch health is recorded, deactivating sources that keep
+// failing; feeds cancelled through ctx are not held against their sources. It also
+// returns the per-feed fetch results, whose validators are saved once the issue is
+// sent. It returns no articles, after logging why, when nothing is left to analyze.
+func fetchNewArticles(ctx context.Context, fetcher *feeds.Fetcher, trail *report.Trail) ([]models.Article, []feeds.FeedResult) {
+	maxFailures, err := config.SourceMaxFailures()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Fetch articles from RSS feeds (now pulls from database)
+	feedList := feeds.GetAllFeeds()
+	log.Printf("Fetching articles from %d feeds from database across %d categories...", len(feedList), len(feeds.GetCategories()))
+	articles, results, err := fetcher.FetchAll(ctx, feedList)
+	feeds.RecordHealth(results, maxFailures)
+	if err != nil {
+		log.Fatalf("Failed to fetch articles: %v", err)
+	}
+	log.Printf("Fetched %d articles", len(articles))
+	trail.Fetched(articles)
+
+	if len(articles) == 0 {
+		log.Println("No articles found (feeds unchanged since the last run or empty), exiting")
+		return nil, results
+	}
+
+	// Filter articles to last 24 hours
+	cutoff := time.Now().Add(-24 * time.Hour)
+	var recentArticles []models.Article
+	for _, article := range articles {
+		if article.Published.After(cutoff) || article.Published.IsZero() {
+			recentArticles = append(recentArticles, article)
+		} else {
+			trail.Filtered(article, "published more than 24 hours ago")
+		}
+	}
+	log.Printf("Filtered to %d articles from last 24 hours (from %d total)\n", len(recentArticles), len(articles))
+	articles = recentArticles
+
+	if len(articles) == 0 {
+		log.Println("No recent articles found, exiting")
+		return nil, results
+	}
+
+	// Filter out articles sent in the last 30 days
+	recentArticleURLs, err := database.GetRecentArticleURLs(30)
+	if err != nil {
+		log.Printf("Warning: Failed to get recent article URLs: %v", err)
+		recentArticleURLs = make(map[string]bool)
+	}
+
+	var newArticles []models.Article
+	for _, article := range articles {
+		if !recentArticleURLs[article.Link] {
+			newArticles = append(newArticles, article)
+		} else {
+			trail.Filtered(article, "already sent in the last 30 days")
+		}
+	}
+
+	duplicatesFiltered := len(articles) - len(newArticles)
+	if duplicatesFiltered > 0 {
+		log.Printf("Filtered out %d duplicate articles sent in the last 30 days", duplicatesFiltered)
+	}
+	articles = newArticles
+
+	if len(articles) == 0 {
+		log.Println("No new articles found (all were sent recently), exiting")
+		return nil, results
+	}
+
+	return articles, results
+}
+
+// subscribedUsers returns the subscribed users who receive the weekly roundup, or those
+// who receive daily issues
+func subscribedUsers(weekly bool) ([]database.User, error) {
+	users, err := database.GetAllSubscribedUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	var matching []database.User
+	for _, user := range users {
+		if user.IsWeekly() == weekly {
+			matching = append(matching, user)
+		}
+	}
+	return matching, nil
+}
+
+// userSummaryStyle returns the user's preferred summary style, or the default if unset or unknown
+func userSummaryStyle(user database.User, defaultStyle string) string {
+	if models.ValidSummaryStyle(user.SummaryStyle) {
+		return user.SummaryStyle
+	}
+	return defaultStyle
+}
+
+// summaryStylesFor returns the distinct summary styles the users' emails need
+func summaryStylesFor(users []database.User, defaultStyle string) []string {
+	seen := make(map[string]bool)
+	var styles []string
+	for _, user := range users {
+		style := userSummaryStyle(user, defaultStyle)
+		if !seen[style] {
+			seen[style] = true
+			styles = append(styles, style)
+		}
+	}
+	return styles
+}
+
+// saveEmailRecord records the newsletter and its selected articles, returning the email record
+func saveEmailRecord(subject string, editorial models.Editorial, articles []models.Article, sourceCount, recipientCount int, selectedArticles []models.AnalyzedArticle, promptVersion string) *database.EmailSent {
+	emailRecord, err := database.CreateEmailSent(
+		subject,
+		len(articles),
+		sourceCount,
+		recipientCount,
+		editorial,
+		email.OpenTracking(),
+	)
+	if err != nil {
+		log.Fatalf("Failed to create email record: %v", err)
+	}
+	log.Printf("✓ Email record created (ID: %d)", emailRecord.ID)
+
+	saveEmailArticles(emailRecord.ID, nil, selectedArticles, promptVersion)
+	log.Printf("✓ Saved %d articles to database", len(selectedArticles))
+	return emailRecord
+}
+
+// saveEmailArticles records the articles of an email with their related coverage. userID
+// is set for a subscriber's personalized picks and nil for the shared selection.
+func saveEmailArticles(emailID uint, userID *uint, selectedArticles []models.AnalyzedArticle, promptVersion string) {
+	for i, article := range selectedArticles {
+		emailArticle, err := database.CreateEmailArticle(
+			emailID,
+			userID,
+			article.Link,
+			article.Title,
+			article.Source,
+			article.RelevanceScore,
+			article.RawScore,
+			article.Category,
+			article.Tags,
+			article.Summary,
+			article.Bullets,
+			article.WhyItMatters,
+			article.Published,
+			i+1, // position (1-indexed)
+			promptVersion,
+		)
+		if err != nil {
+			log.Printf("Warning: Failed to save article to database: %v", err)
+			continue
+		}
+		if err := database.CreateEmailArticleLinks(emailArticle.ID, article.AlsoCoveredBy); err != nil {
+			log.Printf("Warning: Failed to save related coverage to database: %v", err)
+		}
+	}
+}
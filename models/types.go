@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // Article represents a single article from an RSS feed
 type Article struct {
@@ -14,18 +17,194 @@ type Article struct {
 
 // Config holds application configuration
 type Config struct {
-	GeminiAPIKey    string
-	SendGridAPIKey  string
-	FromEmail       string
-	GeminiRateLimit time.Duration
+	GeminiAPIKey         string
+	SendGridAPIKey       string
+	FromEmail            string
+	GeminiRateLimit      time.Duration
+	AIProvider           string // LLM backend: "gemini", "openai" or "fake"
+	AIModel              string // Model name; empty uses the provider default
+	OpenAIBaseURL        string // Base URL of an OpenAI-compatible API
+	OpenAIAPIKey         string
+	ScoreBatchSize       int             // Articles scored per LLM call; 1 disables batching
+	LLMCacheTTL          time.Duration   // How long cached LLM results are reused; 0 disables caching
+	AIRequestsPerMinute  int             // Shared request rate limit; 0 disables it
+	AITokensPerMinute    int             // Shared estimated token rate limit; 0 disables it
+	AIConcurrency        int             // Concurrent LLM requests
+	AIRetryMaxAttempts   int             // Attempts per LLM request, including the first
+	AIRetryMaxElapsed    time.Duration   // Time budget for retrying one request; 0 means no limit
+	AIEmbeddingModel     string          // Embedding model name; empty uses the provider default
+	DedupThreshold       float64         // Cosine similarity at which articles are the same story; 0 disables
+	CoverageBoost        float64         // Score bonus per doubling of sources covering a story
+	PromptsDir           string          // Directory of prompt template overrides; empty uses the built-in prompts
+	AIPriceInputPerMTok  float64         // USD per million prompt tokens, for cost accounting
+	AIPriceOutputPerMTok float64         // USD per million output tokens
+	AIDailyBudgetUSD     float64         // LLM spend allowed per day across runs; 0 disables the budget
+	AIBudgetMode         string          // "degrade" or "abort" once the daily budget is spent
+	SummaryStyle         string          // Default summary style for subscribers without a preference
+	ExtractFullText      bool            // Fetch selected articles' pages and summarize their main text
+	ExtractMaxChars      int             // Cap on extracted text; 0 means no cap
+	Selection            SelectionPolicy // How articles are picked for the email
+	ScoreCalibration     string          // "percentile", "zscore" or "off"
+	ScoreHistoryDays     int             // Days of earlier scores calibration compares against
+}
+
+// FetchConfig controls how feeds are downloaded
+type FetchConfig struct {
+	Timeout      time.Duration // Total time allowed for one feed request, including the body
+	MaxBodyBytes int64         // Larger feeds are rejected
+	UserAgent    string        // Empty uses the built-in User-Agent
+	Concurrency  int           // Feeds fetched at once across all hosts
+	PerHost      int           // Requests in flight at once to a single host
+	HostDelay    time.Duration // Minimum time between the starts of requests to a single host
+}
+
+// SelectionPolicy controls how articles are picked from the ranked list
+type SelectionPolicy struct {
+	TopN           int                      `json:"top_n"`            // Articles per email
+	MinScore       float64                  `json:"min_score"`        // Articles with a calibrated score below this are never included
+	MaxPerCategory int                      `json:"max_per_category"` // Default cap per category; 0 means no cap
+	MaxPerSource   int                      `json:"max_per_source"`   // Cap per feed; 0 means no cap
+	Categories     map[string]CategoryQuota `json:"categories"`       // Per-category overrides, by category name
+}
+
+// CategoryQuota bounds how many articles of one category are selected
+type CategoryQuota struct {
+	Min int `json:"min"` // Guaranteed slots, filled first when the category has eligible articles
+	Max int `json:"max"` // Cap for the category; 0 uses the policy's MaxPerCategory
+}
+
+// DefaultSelectionPolicy returns the policy used when none is configured
+func DefaultSelectionPolicy() SelectionPolicy {
+	return SelectionPolicy{TopN: 8, MaxPerCategory: 2}
+}
+
+// MaxFor returns the cap for a category; 0 means no cap
+func (p SelectionPolicy) MaxFor(category string) int {
+	if quota, ok := p.Categories[category]; ok && quota.Max > 0 {
+		return quota.Max
+	}
+	return p.MaxPerCategory
+}
+
+// Validate checks that the policy's quotas are consistent
+func (p SelectionPolicy) Validate() error {
+	if p.TopN < 1 {
+		return fmt.Errorf("top_n must be at least 1, got %d", p.TopN)
+	}
+	if p.MaxPerCategory < 0 || p.MaxPerSource < 0 {
+		return fmt.Errorf("max_per_category and max_per_source must not be negative")
+	}
+
+	guaranteed := 0
+	for category, quota := range p.Categories {
+		if quota.Min < 0 || quota.Max < 0 {
+			return fmt.Errorf("category %q: min and max must not be negative", category)
+		}
+		if limit := p.MaxFor(category); limit > 0 && quota.Min > limit {
+			return fmt.Errorf("category %q: min %d is above its max %d", category, quota.Min, limit)
+		}
+		guaranteed += quota.Min
+	}
+	if guaranteed > p.TopN {
+		return fmt.Errorf("guaranteed category slots (%d) exceed top_n (%d)", guaranteed, p.TopN)
+	}
+	return nil
 }
 
 // AnalyzedArticle wraps an Article with AI analysis results
 type AnalyzedArticle struct {
 	Article
-	RelevanceScore float64
+	RelevanceScore float64 // Calibrated score on a 0-10 scale, used for ranking and thresholds
+	RawScore       float64 // Score from the LLM before calibration
 	Summary        string
 	Tags           []string
 	Category       string
 	Selected       bool
+	AlsoCoveredBy  []RelatedArticle // Other articles about the same story, merged during deduplication
+	Bullets        []string         // Key takeaways, if the bullets summary style was requested
+	WhyItMatters   string           // Why the story matters, if that summary style was requested
+}
+
+// OtherSources returns one related article per source that also covered the story,
+// excluding the article's own source
+func (a AnalyzedArticle) OtherSources() []RelatedArticle {
+	seen := map[string]bool{a.Source: true}
+	var others []RelatedArticle
+	for _, related := range a.AlsoCoveredBy {
+		if seen[related.Source] {
+			continue
+		}
+		seen[related.Source] = true
+		others = append(others, related)
+	}
+	return others
+}
+
+// Summary styles a subscriber can choose
+const (
+	SummaryOneLiner     = "one-liner"      // A single sentence (AnalyzedArticle.Summary)
+	SummaryBullets      = "bullets"        // Three key takeaways
+	SummaryWhyItMatters = "why-it-matters" // A short paragraph on why the story matters
+)
+
+// Newsletter frequencies a subscriber can choose
+const (
+	FrequencyDaily  = "daily"
+	FrequencyWeekly = "weekly" // A weekly roundup built from the past week's daily issues
+)
+
+// SummaryStyles lists the valid summary styles
+var SummaryStyles = []string{SummaryOneLiner, SummaryBullets, SummaryWhyItMatters}
+
+// ValidSummaryStyle reports whether style is one of SummaryStyles
+func ValidSummaryStyle(style string) bool {
+	for _, valid := range SummaryStyles {
+		if style == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Editorial is the intro paragraph and subject teaser written for a day's selection
+type Editorial struct {
+	Intro  string // "Today in tech" paragraph synthesizing the selected stories
+	Teaser string // One-line subject teaser
+}
+
+// RelatedArticle is another source's article about the same story
+type RelatedArticle struct {
+	Title  string
+	Link   string
+	Source string
+}
+
+// StageUsage is the LLM token usage and estimated cost of one pipeline stage
+type StageUsage struct {
+	Stage        string // "score", "tag", "summarize", "personalize" or "intro"
+	Requests     int
+	PromptTokens int
+	OutputTokens int
+	CostUSD      float64
+}
+
+// LLMCacheKey identifies a cached LLM result for an article
+type LLMCacheKey struct {
+	URL           string
+	ContentHash   string // Hash of the article text the prompt was built from
+	Task          string
+	PromptVersion string
+	Model         string
+}
+
+// UserProfile describes a subscriber's interests for personalized selection
+type UserProfile struct {
+	Interests           string   // Free-text description of what the user cares about
+	PreferredCategories []string // Categories ranked higher for this user
+	BlockedCategories   []string // Categories never shown to this user
+}
+
+// IsEmpty reports whether the profile has no personalization settings
+func (p UserProfile) IsEmpty() bool {
+	return p.Interests == "" && len(p.PreferredCategories) == 0 && len(p.BlockedCategories) == 0
 }
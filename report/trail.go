@@ -0,0 +1,205 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+// Article outcomes, in the order they are listed in a report
+const (
+	OutcomeSelected   = "selected"
+	OutcomeRejected   = "rejected"
+	OutcomeMerged     = "merged"     // Grouped into another article covering the same story
+	OutcomeFiltered   = "filtered"   // Dropped before scoring
+	OutcomeUnfinished = "unfinished" // The run stopped before a decision was made
+)
+
+var outcomeOrder = map[string]int{
+	OutcomeSelected:   0,
+	OutcomeRejected:   1,
+	OutcomeMerged:     2,
+	OutcomeFiltered:   3,
+	OutcomeUnfinished: 4,
+}
+
+// Step is one pipeline stage's decision about an article
+type Step struct {
+	Stage  string `json:"stage"`
+	Detail string `json:"detail"`
+}
+
+// Entry is the decision trail of a single article
+type Entry struct {
+	Title      string    `json:"title"`
+	Link       string    `json:"link"`
+	Source     string    `json:"source"`
+	Published  time.Time `json:"published,omitzero"`
+	Outcome    string    `json:"outcome"`
+	Reason     string    `json:"reason,omitempty"`
+	Scored     bool      `json:"scored"`
+	Score      float64   `json:"score"`       // Raw relevance score from the LLM
+	FinalScore float64   `json:"final_score"` // Calibrated score used for selection, including the coverage boost
+	Rank       int       `json:"rank,omitempty"`
+	Category   string    `json:"category,omitempty"`
+	Tags       []string  `json:"tags,omitempty"`
+	MergedInto string    `json:"merged_into,omitempty"` // Link of the article kept for the story
+	Steps      []Step    `json:"steps"`
+}
+
+// Report is the decision trail of every article in a run
+type Report struct {
+	Generated time.Time      `json:"generated"`
+	Counts    map[string]int `json:"counts"` // Articles by outcome
+	Articles  []Entry        `json:"articles"`
+}
+
+// Trail collects per-article decisions as the pipeline runs. It implements
+// ai.DecisionRecorder and is safe for concurrent use.
+type Trail struct {
+	mu      sync.Mutex
+	entries map[string]*Entry // By link
+	order   []string          // Links in the order they were fetched
+}
+
+// NewTrail creates an empty decision trail
+func NewTrail() *Trail {
+	return &Trail{entries: make(map[string]*Entry)}
+}
+
+// Fetched records articles as fetched from their feeds
+func (t *Trail) Fetched(articles []models.Article) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, article := range articles {
+		detail := fmt.Sprintf("from %s", article.Source)
+		if !article.Published.IsZero() {
+			detail += fmt.Sprintf(", published %s", article.Published.Format(time.RFC1123))
+		}
+		t.step(article, "fetched", detail)
+	}
+}
+
+// Filtered records that an article was dropped before scoring
+func (t *Trail) Filtered(article models.Article, reason string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry := t.step(article, "filtered", reason)
+	entry.Outcome, entry.Reason = OutcomeFiltered, reason
+}
+
+// Scored records an article's relevance score
+func (t *Trail) Scored(article models.Article, score float64, cached bool, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	detail := fmt.Sprintf("%.1f", score)
+	switch {
+	case err != nil:
+		detail = fmt.Sprintf("failed, scored 0: %v", err)
+	case cached:
+		detail += " (cached)"
+	}
+	entry := t.step(article, "scored", detail)
+	entry.Scored, entry.Score, entry.FinalScore = true, score, score
+}
+
+// Merged records that an article was grouped into another covering the same story
+func (t *Trail) Merged(article models.AnalyzedArticle, into models.AnalyzedArticle, similarity float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	reason := fmt.Sprintf("same story as '%s' from %s (similarity %.2f)", into.Title, into.Source, similarity)
+	entry := t.step(article.Article, "deduplicated", reason)
+	entry.Outcome, entry.Reason, entry.MergedInto = OutcomeMerged, reason, into.Link
+
+	t.step(into.Article, "deduplicated", fmt.Sprintf("also covered by '%s' from %s", article.Title, article.Source))
+}
+
+// Tagged records an article's category and tags
+func (t *Trail) Tagged(article models.AnalyzedArticle, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	detail := fmt.Sprintf("category %s, tags %v", article.Category, article.Tags)
+	if err != nil {
+		detail = fmt.Sprintf("failed, fell back to category %s: %v", article.Category, err)
+	}
+	entry := t.step(article.Article, "tagged", detail)
+	entry.Category, entry.Tags = article.Category, article.Tags
+}
+
+// Selected records that an article was picked for the email
+func (t *Trail) Selected(article models.AnalyzedArticle, rank int, reason string) {
+	t.decide(article, rank, OutcomeSelected, reason)
+}
+
+// Rejected records why an article was not picked for the email
+func (t *Trail) Rejected(article models.AnalyzedArticle, rank int, reason string) {
+	t.decide(article, rank, OutcomeRejected, reason)
+}
+
+// decide records the selection outcome of a ranked article
+func (t *Trail) decide(article models.AnalyzedArticle, rank int, outcome, reason string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry := t.step(article.Article, outcome, fmt.Sprintf("ranked #%d at %.1f: %s", rank, article.RelevanceScore, reason))
+	entry.Outcome, entry.Reason = outcome, reason
+	entry.Rank, entry.FinalScore = rank, article.RelevanceScore
+	entry.Category, entry.Tags = article.Category, article.Tags
+}
+
+// step appends a step to the article's entry, creating the entry if needed. The caller
+// must hold t.mu.
+func (t *Trail) step(article models.Article, stage, detail string) *Entry {
+	entry, ok := t.entries[article.Link]
+	if !ok {
+		entry = &Entry{
+			Title:     article.Title,
+			Link:      article.Link,
+			Source:    article.Source,
+			Published: article.Published,
+		}
+		t.entries[article.Link] = entry
+		t.order = append(t.order, article.Link)
+	}
+	entry.Steps = append(entry.Steps, Step{Stage: stage, Detail: detail})
+	return entry
+}
+
+// Report returns a snapshot of the trail. Articles are ordered by outcome, then by rank
+// for ranked articles and fetch order for the rest.
+func (t *Trail) Report() Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := Report{
+		Generated: time.Now(),
+		Counts:    make(map[string]int),
+		Articles:  make([]Entry, 0, len(t.order)),
+	}
+	for _, link := range t.order {
+		entry := *t.entries[link]
+		entry.Steps = append([]Step(nil), entry.Steps...)
+		if entry.Outcome == "" {
+			entry.Outcome = OutcomeUnfinished
+		}
+		report.Counts[entry.Outcome]++
+		report.Articles = append(report.Articles, entry)
+	}
+
+	sort.SliceStable(report.Articles, func(i, j int) bool {
+		a, b := report.Articles[i], report.Articles[j]
+		if a.Outcome != b.Outcome {
+			return outcomeOrder[a.Outcome] < outcomeOrder[b.Outcome]
+		}
+		return a.Rank < b.Rank
+	})
+	return report
+}
@@ -0,0 +1,151 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// outcomeColors are the badge colors of each outcome in the HTML report
+var outcomeColors = map[string]string{
+	OutcomeSelected:   "#2ecc71",
+	OutcomeRejected:   "#e67e22",
+	OutcomeMerged:     "#9b59b6",
+	OutcomeFiltered:   "#95a5a6",
+	OutcomeUnfinished: "#e74c3c",
+}
+
+// Write saves the report as selection-<date>.json and selection-<date>.html in dir,
+// creating dir if needed, and returns the paths written
+func (r Report) Write(dir string) (jsonPath, htmlPath string, err error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", "", fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	name := "selection-" + r.Generated.Format("2006-01-02")
+	jsonPath = filepath.Join(dir, name+".json")
+	htmlPath = filepath.Join(dir, name+".html")
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode report: %w", err)
+	}
+	if err := os.WriteFile(jsonPath, append(data, '\n'), 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to write %s: %w", jsonPath, err)
+	}
+	if err := os.WriteFile(htmlPath, []byte(r.HTML()), 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to write %s: %w", htmlPath, err)
+	}
+	return jsonPath, htmlPath, nil
+}
+
+// HTML renders the report as a standalone page with a row per article. Each row expands
+// to show the article's full decision trail.
+func (r Report) HTML() string {
+	var sb strings.Builder
+	sb.WriteString(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="UTF-8">
+	<title>The Paper - Selection Report</title>
+	<style>
+		body {
+			font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif;
+			color: #333;
+			margin: 0 auto;
+			padding: 20px;
+			max-width: 1100px;
+		}
+		h1 {
+			color: #2c3e50;
+			border-bottom: 3px solid #3498db;
+			padding-bottom: 10px;
+		}
+		.counts span {
+			display: inline-block;
+			margin-right: 16px;
+		}
+		table {
+			width: 100%;
+			border-collapse: collapse;
+			font-size: 14px;
+		}
+		th, td {
+			text-align: left;
+			vertical-align: top;
+			padding: 8px;
+			border-bottom: 1px solid #ecf0f1;
+		}
+		th {
+			color: #7f8c8d;
+		}
+		.outcome {
+			display: inline-block;
+			color: white;
+			padding: 2px 8px;
+			border-radius: 10px;
+			font-size: 12px;
+		}
+		summary {
+			cursor: pointer;
+		}
+		ol {
+			margin: 6px 0 0 0;
+			color: #555;
+		}
+		.stage {
+			font-weight: 600;
+		}
+	</style>
+</head>
+<body>
+`)
+	sb.WriteString(fmt.Sprintf("\t<h1>Selection Report</h1>\n\t<p>Generated %s</p>\n", html.EscapeString(r.Generated.Format("Monday, January 2, 2006 at 15:04"))))
+
+	sb.WriteString("\t<p class=\"counts\">\n")
+	for _, outcome := range []string{OutcomeSelected, OutcomeRejected, OutcomeMerged, OutcomeFiltered, OutcomeUnfinished} {
+		if count := r.Counts[outcome]; count > 0 {
+			sb.WriteString(fmt.Sprintf("\t\t<span><strong>%d</strong> %s</span>\n", count, outcome))
+		}
+	}
+	sb.WriteString("\t</p>\n")
+
+	sb.WriteString(`	<table>
+		<tr><th>Outcome</th><th>Rank</th><th>Score</th><th>Article</th><th>Category</th><th>Reason</th></tr>
+`)
+	for _, entry := range r.Articles {
+		rank := ""
+		if entry.Rank > 0 {
+			rank = fmt.Sprintf("#%d", entry.Rank)
+		}
+		score := ""
+		if entry.Scored {
+			score = fmt.Sprintf("%.1f", entry.FinalScore)
+		}
+		if entry.Scored && entry.FinalScore != entry.Score {
+			score = fmt.Sprintf("%.1f → %.1f", entry.Score, entry.FinalScore)
+		}
+
+		var steps strings.Builder
+		for _, step := range entry.Steps {
+			steps.WriteString(fmt.Sprintf("<li><span class=\"stage\">%s</span>: %s</li>", html.EscapeString(step.Stage), html.EscapeString(step.Detail)))
+		}
+
+		sb.WriteString(fmt.Sprintf(`		<tr>
+			<td><span class="outcome" style="background-color: %s;">%s</span></td>
+			<td>%s</td>
+			<td>%s</td>
+			<td><details><summary><a href="%s" target="_blank">%s</a> (%s)</summary><ol>%s</ol></details></td>
+			<td>%s</td>
+			<td>%s</td>
+		</tr>
+`, outcomeColors[entry.Outcome], entry.Outcome, rank, score,
+			html.EscapeString(entry.Link), html.EscapeString(entry.Title), html.EscapeString(entry.Source), steps.String(),
+			html.EscapeString(entry.Category), html.EscapeString(entry.Reason)))
+	}
+	sb.WriteString("\t</table>\n</body>\n</html>\n")
+	return sb.String()
+}
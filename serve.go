@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/ty-e-boyd/thepaper/database"
+)
+
+// trackingPixel is a transparent 1x1 GIF
+var trackingPixel = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// runServeCommand serves the open-tracking pixel that emails embed when TRACKING_URL is
+// set, recording opens on user_emails for the weekly roundup's engagement ranking
+func runServeCommand(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "Address to listen on")
+	fs.Parse(args)
+
+	connectDatabase()
+	defer database.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /open", handleOpen)
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("Serving open tracking on %s", *addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("Failed to serve: %v", err)
+	}
+}
+
+// handleOpen records that the subscriber with the token opened the email, then returns
+// the pixel. The pixel is returned whatever the outcome, so a request reveals nothing
+// about tokens or emails; repeat opens keep the first open time.
+func handleOpen(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		w.Header().Set("Content-Type", "image/gif")
+		w.Header().Set("Cache-Control", "no-store, max-age=0")
+		w.Write(trackingPixel)
+	}()
+
+	emailID, err := strconv.ParseUint(r.URL.Query().Get("email"), 10, 64)
+	token := r.URL.Query().Get("token")
+	if err != nil || token == "" {
+		return
+	}
+
+	user, err := database.GetUserByToken(token)
+	if err != nil {
+		return
+	}
+	userEmail, err := database.GetUserEmail(user.ID, uint(emailID))
+	if err != nil || userEmail.Opened {
+		return
+	}
+	if err := database.MarkEmailOpened(userEmail.ID); err != nil {
+		log.Printf("Warning: Failed to record open of email %d by %s: %v", emailID, user.Email, err)
+	}
+}
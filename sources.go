@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ty-e-boyd/thepaper/config"
+	"github.com/ty-e-boyd/thepaper/database"
+	"github.com/ty-e-boyd/thepaper/feeds"
+)
+
+// runSourcesCommand handles "sources" subcommands
+func runSourcesCommand(args []string) {
+	if len(args) == 0 {
+		sourcesUsage()
+	}
+
+	switch args[0] {
+	case "health":
+		runSourcesHealthCommand(args[1:])
+	case "add":
+		runSourcesAddCommand(args[1:])
+	case "import":
+		runSourcesImportCommand(args[1:])
+	case "export":
+		runSourcesExportCommand(args[1:])
+	default:
+		sourcesUsage()
+	}
+}
+
+// sourcesUsage prints the "sources" subcommands and exits
+func sourcesUsage() {
+	fmt.Fprintln(os.Stderr, `Usage:
+  thepaper sources health [--all]
+  thepaper sources add [--category NAME] [--name NAME] [--feed N] [--dry-run] <url>
+  thepaper sources import [--update] [--category NAME] <file.opml>
+  thepaper sources export [--all] [--out FILE]`)
+	os.Exit(2)
+}
+
+// runSourcesHealthCommand reports the fetch health of degraded sources: those that are
+// failing, were deactivated after a failure streak, or were empty when last downloaded
+func runSourcesHealthCommand(args []string) {
+	fs := flag.NewFlagSet("sources health", flag.ExitOnError)
+	all := fs.Bool("all", false, "List every source, not only degraded ones")
+	fs.Parse(args)
+
+	maxFailures, err := config.SourceMaxFailures()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	connectDatabase()
+	defer database.Close()
+
+	sources, err := database.GetAllSources()
+	if err != nil {
+		log.Fatalf("Failed to get sources: %v", err)
+	}
+
+	var degraded []database.Source
+	for _, source := range sources {
+		if *all || sourceDegraded(source) {
+			degraded = append(degraded, source)
+		}
+	}
+	sort.SliceStable(degraded, func(i, j int) bool {
+		if degraded[i].ConsecutiveFailures != degraded[j].ConsecutiveFailures {
+			return degraded[i].ConsecutiveFailures > degraded[j].ConsecutiveFailures
+		}
+		return degraded[i].Name < degraded[j].Name
+	})
+
+	threshold := "never deactivated"
+	if maxFailures > 0 {
+		threshold = fmt.Sprintf("deactivated after %d consecutive failures", maxFailures)
+	}
+	if *all {
+		log.Printf("Source health (%d sources, %s):", len(sources), threshold)
+	} else {
+		log.Printf("Degraded sources (%d of %d, %s):", len(degraded), len(sources), threshold)
+	}
+
+	for _, source := range degraded {
+		status := "✓"
+		switch {
+		case source.QuarantinedAt != nil && !source.Active:
+			status = fmt.Sprintf("⊘ quarantined %s", source.QuarantinedAt.Format("2006-01-02"))
+		case !source.Active:
+			status = "⊘ inactive"
+		case source.ConsecutiveFailures > 0:
+			status = "✗ failing"
+		case sourceDegraded(source):
+			status = "⚠ empty"
+		}
+
+		log.Printf("  %s  %s (%s)", status, source.Name, source.URL)
+		log.Printf("      Last success: %s | Items: %d | Avg latency: %.0f ms",
+			formatSourceTime(source.LastSuccessAt), source.LastItemCount, source.AvgLatencyMs)
+		if source.ConsecutiveFailures > 0 || source.QuarantinedAt != nil {
+			log.Printf("      %d consecutive failure(s), last at %s: %s",
+				source.ConsecutiveFailures, formatSourceTime(source.LastErrorAt), source.LastError)
+		}
+	}
+}
+
+// runSourcesAddCommand discovers the feeds of a website and adds one as a source. The
+// discovered feeds are listed with their titles and item counts; the first is added
+// unless --feed picks another.
+func runSourcesAddCommand(args []string) {
+	fs := flag.NewFlagSet("sources add", flag.ExitOnError)
+	category := fs.String("category", "", "Category of the new source (required unless --dry-run)")
+	name := fs.String("name", "", "Source name (default: the suggested name)")
+	pick := fs.Int("feed", 1, "Which discovered feed to add, by its number in the list")
+	dryRun := fs.Bool("dry-run", false, "List the discovered feeds without adding a source")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		sourcesUsage()
+	}
+
+	fetchCfg, err := config.LoadFetch()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	log.Printf("Discovering feeds for %s...", fs.Arg(0))
+	discovered, err := feeds.NewFetcher(fetchCfg).Discover(context.Background(), fs.Arg(0))
+	if err != nil {
+		log.Fatalf("Feed discovery failed: %v", err)
+	}
+
+	log.Printf("Found %d feed(s):", len(discovered))
+	for i, feed := range discovered {
+		title := feed.Title
+		if title == "" {
+			title = "(untitled)"
+		}
+		log.Printf("  %d. %s — %d items", i+1, title, feed.Items)
+		log.Printf("     %s (suggested name: %s)", feed.URL, feed.Name)
+	}
+	if *dryRun {
+		return
+	}
+	if *pick < 1 || *pick > len(discovered) {
+		log.Fatalf("--feed must be between 1 and %d", len(discovered))
+	}
+	feed := discovered[*pick-1]
+	if *name == "" {
+		*name = feed.Name
+	}
+
+	connectDatabase()
+	defer database.Close()
+
+	// Match an existing category regardless of case so sources are not split across spellings
+	categories := feeds.GetCategories()
+	if strings.TrimSpace(*category) == "" {
+		log.Fatalf("--category is required (existing categories: %s)", strings.Join(categories, ", "))
+	}
+	*category = strings.TrimSpace(*category)
+	isNew := true
+	for _, existing := range categories {
+		if strings.EqualFold(existing, *category) {
+			*category = existing
+			isNew = false
+			break
+		}
+	}
+
+	if existing, err := database.GetSourceByURL(feed.URL); err == nil && existing != nil {
+		log.Fatalf("%s is already a source: %s [%s] (ID: %d)", feed.URL, existing.Name, existing.Category, existing.ID)
+	}
+	source, err := database.CreateSource(*name, *category, feed.URL, true)
+	if err != nil {
+		log.Fatalf("Failed to add source: %v", err)
+	}
+	if isNew {
+		log.Printf("  New category: %s", source.Category)
+	}
+	log.Printf("✓ Added: %s [%s] %s (ID: %d)", source.Name, source.Category, source.URL, source.ID)
+}
+
+// runSourcesImportCommand adds the feeds of an OPML file as sources, using each feed's
+// outline group as its category. Feeds already in the database are skipped, or with
+// --update get the file's name and category.
+func runSourcesImportCommand(args []string) {
+	fs := flag.NewFlagSet("sources import", flag.ExitOnError)
+	update := fs.Bool("update", false, "Update the name and category of sources already in the database")
+	defaultCategory := fs.String("category", "Uncategorized", "Category for feeds outside any outline group")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		sourcesUsage()
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("Failed to open OPML file: %v", err)
+	}
+	opmlFeeds, err := feeds.ParseOPML(file)
+	file.Close()
+	if err != nil {
+		log.Fatalf("Failed to read %s: %v", fs.Arg(0), err)
+	}
+
+	connectDatabase()
+	defer database.Close()
+
+	log.Printf("Importing %d feeds from %s...", len(opmlFeeds), fs.Arg(0))
+	added, updated, skipped, failed := 0, 0, 0, 0
+	for _, feed := range opmlFeeds {
+		category := feed.Category
+		if category == "" {
+			category = *defaultCategory
+		}
+		name := feed.Title
+		if name == "" {
+			name = feed.URL
+		}
+
+		existing, err := database.GetSourceByURL(feed.URL)
+		if err == nil && existing != nil {
+			if !*update || (existing.Name == name && existing.Category == category) {
+				log.Printf("  ⊘ Skipped (already exists): %s", feed.URL)
+				skipped++
+				continue
+			}
+			if err := database.UpdateSourceDetails(existing.ID, name, category); err != nil {
+				log.Printf("  ✗ Failed to update source %s: %v", feed.URL, err)
+				failed++
+				continue
+			}
+			log.Printf("  ↻ Updated: %s (%s → %s, %s → %s)", feed.URL, existing.Name, name, existing.Category, category)
+			updated++
+			continue
+		}
+
+		source, err := database.CreateSource(name, category, feed.URL, true)
+		if err != nil {
+			log.Printf("  ✗ Failed to create source %s: %v", feed.URL, err)
+			failed++
+			continue
+		}
+		log.Printf("  ✓ Added: %s [%s] %s (ID: %d)", source.Name, source.Category, source.URL, source.ID)
+		added++
+	}
+
+	log.Printf("Import complete: %d added, %d updated, %d skipped, %d failed", added, updated, skipped, failed)
+}
+
+// runSourcesExportCommand writes the sources as an OPML file grouped by category, to
+// stdout unless --out is given
+func runSourcesExportCommand(args []string) {
+	fs := flag.NewFlagSet("sources export", flag.ExitOnError)
+	all := fs.Bool("all", false, "Include inactive sources")
+	out := fs.String("out", "", "Write to this file instead of stdout")
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		sourcesUsage()
+	}
+
+	connectDatabase()
+	defer database.Close()
+
+	var sources []database.Source
+	var err error
+	if *all {
+		sources, err = database.GetAllSources()
+	} else {
+		sources, err = database.GetAllActiveSources()
+	}
+	if err != nil {
+		log.Fatalf("Failed to get sources: %v", err)
+	}
+
+	opmlFeeds := make([]feeds.OPMLFeed, 0, len(sources))
+	for _, source := range sources {
+		opmlFeeds = append(opmlFeeds, feeds.OPMLFeed{Title: source.Name, URL: source.URL, Category: source.Category})
+	}
+
+	if *out == "" {
+		if err := feeds.WriteOPML(os.Stdout, "The Paper sources", opmlFeeds); err != nil {
+			log.Fatalf("Failed to export sources: %v", err)
+		}
+		return
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("Failed to create %s: %v", *out, err)
+	}
+	err = feeds.WriteOPML(file, "The Paper sources", opmlFeeds)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Fatalf("Failed to export sources: %v", err)
+	}
+	log.Printf("✓ Exported %d sources to %s", len(opmlFeeds), *out)
+}
+
+// sourceDegraded reports whether a source is failing, quarantined, or had no items the
+// last time its feed was downloaded
+func sourceDegraded(source database.Source) bool {
+	return source.ConsecutiveFailures > 0 || source.QuarantinedAt != nil ||
+		(source.Active && source.LastSuccessAt != nil && source.LastItemCount == 0)
+}
+
+// formatSourceTime formats an optional fetch time for the health report
+func formatSourceTime(t *time.Time) string {
+	if t == nil {
+		return "never"
+	}
+	return t.Format("2006-01-02 15:04")
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/ty-e-boyd/thepaper/config"
+	"github.com/ty-e-boyd/thepaper/database"
+	"github.com/ty-e-boyd/thepaper/email"
+	"github.com/ty-e-boyd/thepaper/models"
+)
+
+const (
+	weeklyDays             = 7   // Days of daily issues a roundup covers
+	weeklyArticlesCount    = 12  // Number of articles in the weekly roundup
+	weeklyMaxPerCategory   = 4   // Cap per category so one busy topic cannot fill the roundup
+	weeklyEngagementWeight = 0.3 // Share of the weekly score taken from the issue's open rate
+)
+
+// weeklyArticle is a daily issue's article with its weekly ranking score
+type weeklyArticle struct {
+	database.EmailArticle
+	WeeklyScore float64
+}
+
+// runWeeklyCommand sends the weekly roundup to weekly subscribers. Rather than fetching
+// feeds, it re-ranks the articles of the past week's daily issues and groups them by category.
+func runWeeklyCommand(args []string) {
+	fs := flag.NewFlagSet("weekly", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "Preview the roundup without sending emails")
+	fs.Parse(args)
+
+	if *dryRun {
+		log.Println("🔍 DRY RUN MODE - No emails will be sent")
+	}
+
+	connectDatabase()
+	defer database.Close()
+
+	// The roundup reuses stored summaries, so only the email settings are needed
+	log.Println("Loading configuration...")
+	cfg, err := config.LoadEmail()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	users, err := subscribedUsers(true)
+	if err != nil {
+		log.Fatalf("Failed to get subscribed users: %v", err)
+	}
+	if len(users) == 0 {
+		log.Println("No weekly subscribers found, exiting")
+		return
+	}
+	log.Printf("Found %d weekly subscriber(s)", len(users))
+
+	history, err := database.GetRecentIssueArticles(weeklyDays)
+	if err != nil {
+		log.Fatalf("Failed to get recent issue articles: %v", err)
+	}
+	if len(history) == 0 {
+		log.Println("No daily issues were sent in the past week, exiting")
+		return
+	}
+
+	issues := make(map[uint]bool)
+	sources := make(map[string]bool)
+	for _, row := range history {
+		issues[row.EmailID] = true
+		sources[row.ArticleSource] = true
+	}
+
+	// Engagement only counts for issues sent with the open-tracking pixel
+	openRates, err := database.GetEmailOpenRates(slices.Collect(maps.Keys(issues)))
+	if err != nil {
+		log.Fatalf("Failed to get open rates: %v", err)
+	}
+	if len(openRates) > 0 {
+		log.Printf("Blending open rates of %d tracked issue(s) into the ranking", len(openRates))
+	} else {
+		log.Println("No issues were sent with open tracking, ranking by relevance score alone")
+	}
+
+	selected := rankWeekly(history, openRates)
+
+	articles := make([]models.AnalyzedArticle, len(selected))
+	for i, article := range selected {
+		articles[i] = article.Analyzed()
+		links, err := database.GetEmailArticleLinks(article.ID)
+		if err != nil {
+			log.Printf("Warning: Failed to get related coverage for '%s': %v", article.ArticleTitle, err)
+		}
+		for _, link := range links {
+			articles[i].AlsoCoveredBy = append(articles[i].AlsoCoveredBy, models.RelatedArticle{Title: link.Title, Link: link.URL, Source: link.Source})
+		}
+	}
+
+	weekEnd := time.Now()
+	weekStart := weekEnd.AddDate(0, 0, -weeklyDays+1)
+	subject := fmt.Sprintf("The Paper Weekly - Week of %s", weekStart.Format("January 2, 2006"))
+
+	if *dryRun {
+		log.Println("\n============================================================")
+		log.Println("🔍 WEEKLY DRY RUN SUMMARY")
+		log.Println("============================================================")
+		log.Printf("📚 Articles from the past week: %d in %d daily issue(s)", len(history), len(issues))
+		log.Printf("👥 Weekly subscribers: %d", len(users))
+		log.Printf("✉️  Subject: %s", subject)
+		log.Println("\n📝 Selected articles:")
+		for i, article := range selected {
+			log.Printf("  %d. [%.1f, weekly %.1f] %s (%s)", i+1, article.RelevanceScore, article.WeeklyScore, article.ArticleTitle, article.Category)
+		}
+		log.Println("\n============================================================")
+		log.Println("✅ Dry run complete - no emails sent")
+		log.Println("============================================================")
+		return
+	}
+
+	emailRecord, err := database.CreateWeeklyEmailSent(subject, len(history), len(sources), len(users), email.OpenTracking())
+	if err != nil {
+		log.Fatalf("Failed to create email record: %v", err)
+	}
+	log.Printf("✓ Weekly email record created (ID: %d)", emailRecord.ID)
+	for i, article := range articles {
+		row := selected[i]
+		emailArticle, err := database.CreateEmailArticle(
+			emailRecord.ID,
+			nil,
+			row.ArticleURL,
+			row.ArticleTitle,
+			row.ArticleSource,
+			row.RelevanceScore,
+			row.RawScore,
+			row.Category,
+			article.Tags,
+			row.Summary,
+			article.Bullets,
+			row.WhyItMatters,
+			row.PublishedAt,
+			i+1, // position (1-indexed)
+			row.PromptVersion,
+		)
+		if err != nil {
+			log.Printf("Warning: Failed to save article to database: %v", err)
+			continue
+		}
+		if err := database.CreateEmailArticleLinks(emailArticle.ID, article.AlsoCoveredBy); err != nil {
+			log.Printf("Warning: Failed to save related coverage to database: %v", err)
+		}
+	}
+
+	sender := email.NewSender(cfg.SendGridAPIKey)
+	successCount := 0
+	failCount := 0
+	for _, user := range users {
+		log.Printf("Sending weekly roundup to %s (%s)...", user.Email, user.Name)
+
+		htmlContent := email.BuildWeeklyHTMLWithToken(articles, weekStart, weekEnd, len(history), len(issues), user.UnsubscribeToken, userSummaryStyle(user, cfg.SummaryStyle), emailRecord.ID)
+		if err := sender.Send(cfg.FromEmail, user.Email, subject, htmlContent); err != nil {
+			log.Printf("  ✗ Failed to send to %s: %v", user.Email, err)
+			failCount++
+			continue
+		}
+
+		if _, err := database.CreateUserEmail(user.ID, emailRecord.ID); err != nil {
+			log.Printf("  Warning: Failed to record email send for %s: %v", user.Email, err)
+		}
+
+		log.Printf("  ✓ Sent successfully to %s", user.Email)
+		successCount++
+	}
+
+	log.Println("\n============================================================")
+	log.Printf("Weekly roundup complete!")
+	log.Printf("Successfully sent: %d", successCount)
+	log.Printf("Failed: %d", failCount)
+	log.Printf("Articles featured: %d", len(articles))
+	log.Printf("============================================================")
+}
+
+// rankWeekly re-ranks the past week's daily articles by relevance score blended with the
+// open rate of the issue they were sent in, picks the top ones with a per-category cap,
+// and orders them by category for the grouped layout. openRates holds the rates of the
+// issues sent with open tracking; the others get the mean tracked rate, so they are
+// neither favored nor penalized, and without any tracked issue the score is used alone.
+func rankWeekly(history []database.EmailArticle, openRates map[uint]float64) []weeklyArticle {
+	neutralRate := 0.0
+	for _, rate := range openRates {
+		neutralRate += rate / float64(len(openRates))
+	}
+	weeklyScore := func(row database.EmailArticle) float64 {
+		if len(openRates) == 0 {
+			return row.RelevanceScore
+		}
+		rate, ok := openRates[row.EmailID]
+		if !ok {
+			rate = neutralRate
+		}
+		// Open rates are scaled to the 0-10 score range before blending
+		return (1-weeklyEngagementWeight)*row.RelevanceScore + weeklyEngagementWeight*10*rate
+	}
+
+	// The same story can appear in more than one issue; keep its best-ranked copy
+	best := make(map[string]weeklyArticle)
+	for _, row := range history {
+		article := weeklyArticle{EmailArticle: row, WeeklyScore: weeklyScore(row)}
+		if existing, ok := best[row.ArticleURL]; !ok || article.WeeklyScore > existing.WeeklyScore {
+			best[row.ArticleURL] = article
+		}
+	}
+
+	ranked := make([]weeklyArticle, 0, len(best))
+	for _, article := range best {
+		ranked = append(ranked, article)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].WeeklyScore != ranked[j].WeeklyScore {
+			return ranked[i].WeeklyScore > ranked[j].WeeklyScore
+		}
+		return ranked[i].ArticleURL < ranked[j].ArticleURL
+	})
+
+	var selected []weeklyArticle
+	perCategory := make(map[string]int)
+	for _, article := range ranked {
+		if len(selected) == weeklyArticlesCount {
+			break
+		}
+		if perCategory[article.Category] >= weeklyMaxPerCategory {
+			continue
+		}
+		perCategory[article.Category]++
+		selected = append(selected, article)
+	}
+
+	// Group by category, ordering categories by their best article
+	categoryRank := make(map[string]int)
+	for _, article := range selected {
+		if _, ok := categoryRank[article.Category]; !ok {
+			categoryRank[article.Category] = len(categoryRank)
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		return categoryRank[selected[i].Category] < categoryRank[selected[j].Category]
+	})
+
+	return selected
+}